@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/niklucky/vodka/builders"
@@ -11,12 +12,30 @@ Adapter - adapter intarface for DataServices
 */
 type Adapter interface {
 	Connect() error
-	Exec(string) (sql.Result, error)
-	QueryRow(string) (*sql.Row, error)
-	Query(...interface{}) (*sql.Rows, error)
+	Exec(string, ...interface{}) (sql.Result, error)
+	QueryRow(string, ...interface{}) (*sql.Row, error)
+	Query(string, ...interface{}) (*sql.Rows, error)
+	ExecBatch([]Statement) error
+	Begin() (*sql.Tx, error)
 	Builder() builders.Builder
 }
 
+/*
+ContextAdapter - adapter that supports context for cancellation and timeouts
+*/
+type ContextAdapter interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+}
+
+/*
+Statement - SQL statement with arguments for ExecBatch
+*/
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
 /*
 Config - Database config
 */
@@ -27,4 +46,41 @@ type Config struct {
 	Port int
 	Database,
 	SSLmode string
+
+	// Secrets - provider of password (resolved on every new connection), Password is ignored if set
+	Secrets SecretProvider `json:"-"`
+	// PasswordKey - key of password in Secrets
+	PasswordKey string `json:"passwordKey"`
+	// TLS - TLS options, SSLmode is ignored if set
+	TLS *TLSConfig `json:"tls"`
+	// ReadOnly - session is set to read-only mode, database rejects all writes
+	ReadOnly bool `json:"readOnly"`
+	// Dialect - name of builder dialect (see builders.Register), adapter default if empty
+	Dialect string `json:"dialect"`
+	// MaxIdleConns - idle connections kept in pool, database/sql default (2) if 0, none if negative.
+	// It's kept when connections are rotated
+	MaxIdleConns int `json:"maxIdleConns"`
+}
+
+// dialectBuilder - builder of config dialect or of adapter default one
+func dialectBuilder(config Config, fallback string) builders.Builder {
+	name := config.Dialect
+	if name == "" {
+		name = fallback
+	}
+	b, err := builders.New(name)
+	if err != nil {
+		// unknown dialect is reported by connect
+		b, _ = builders.New(fallback)
+	}
+	return b
+}
+
+// checkDialect - checking that config dialect is registered
+func checkDialect(config Config) error {
+	if config.Dialect == "" {
+		return nil
+	}
+	_, err := builders.New(config.Dialect)
+	return err
 }
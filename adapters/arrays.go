@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+ParseArray - parsing one-dimensional Postgres array literal {a,"b c",NULL} into elements.
+NULL elements are returned as nil
+*/
+func ParseArray(s string) []*string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil
+	}
+	s = s[1 : len(s)-1]
+	items := []*string{}
+	if s == "" {
+		return items
+	}
+	var item strings.Builder
+	quoted, wasQuoted := false, false
+	flush := func() {
+		v := item.String()
+		if !wasQuoted && v == "NULL" {
+			items = append(items, nil)
+		} else {
+			items = append(items, &v)
+		}
+		item.Reset()
+		wasQuoted = false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			item.WriteByte(s[i])
+		case c == '"':
+			quoted = !quoted
+			wasQuoted = true
+		case c == ',' && !quoted:
+			flush()
+		default:
+			item.WriteByte(c)
+		}
+	}
+	flush()
+	return items
+}
+
+// convertArray - converting array literal by element type ("_INT8" -> []int64)
+func convertArray(dbType, s string) interface{} {
+	items := ParseArray(s)
+	if items == nil {
+		return s
+	}
+	switch dbType {
+	case "_INT2", "_INT4", "_INT8":
+		result := make([]int64, 0, len(items))
+		for _, item := range items {
+			var n int64
+			if item != nil {
+				n, _ = strconv.ParseInt(*item, 10, 64)
+			}
+			result = append(result, n)
+		}
+		return result
+	case "_FLOAT4", "_FLOAT8", "_NUMERIC":
+		result := make([]float64, 0, len(items))
+		for _, item := range items {
+			var f float64
+			if item != nil {
+				f, _ = strconv.ParseFloat(*item, 64)
+			}
+			result = append(result, f)
+		}
+		return result
+	case "_BOOL":
+		result := make([]bool, 0, len(items))
+		for _, item := range items {
+			result = append(result, item != nil && *item == "t")
+		}
+		return result
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			result = append(result, *item)
+		} else {
+			result = append(result, "")
+		}
+	}
+	return result
+}
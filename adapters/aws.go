@@ -0,0 +1,125 @@
+package adapters
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const awsAlgorithm = "AWS4-HMAC-SHA256"
+
+/*
+AWSCredentials - AWS access keys. Use AWSCredentialsFromEnv to read standard AWS_* variables
+*/
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSCredentialsFromEnv - reading AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+func AWSCredentialsFromEnv() AWSCredentials {
+	return AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// signV4 - signing request with AWS Signature Version 4 (Authorization header)
+func signV4(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	req.Header.Set("Host", req.URL.Host)
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var headers string
+	for _, name := range names {
+		headers += name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		awsPath(req.URL),
+		req.URL.Query().Encode(),
+		headers,
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+	scope, signature := awsSignature(canonical, creds, region, service, now)
+	req.Header.Set("Authorization", awsAlgorithm+" Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// presignV4 - building presigned query (signature in query string) valid for expires
+func presignV4(u *url.URL, creds AWSCredentials, region, service string, now time.Time, expires time.Duration) string {
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", awsAlgorithm)
+	q.Set("X-Amz-Credential", creds.AccessKeyID+"/"+now.UTC().Format("20060102")+"/"+region+"/"+service+"/aws4_request")
+	q.Set("X-Amz-Date", now.UTC().Format("20060102T150405Z"))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if creds.SessionToken != "" {
+		q.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	query := strings.Replace(q.Encode(), "+", "%20", -1)
+	canonical := strings.Join([]string{
+		"GET",
+		awsPath(u),
+		query,
+		"host:" + u.Host + "\n",
+		"host",
+		sha256Hex(nil),
+	}, "\n")
+	_, signature := awsSignature(canonical, creds, region, service, now)
+	return query + "&X-Amz-Signature=" + signature
+}
+
+func awsSignature(canonical string, creds AWSCredentials, region, service string, now time.Time) (scope, signature string) {
+	date := now.UTC().Format("20060102")
+	scope = date + "/" + region + "/" + service + "/aws4_request"
+	toSign := strings.Join([]string{
+		awsAlgorithm,
+		now.UTC().Format("20060102T150405Z"),
+		scope,
+		sha256Hex([]byte(canonical)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return scope, hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func awsPath(u *url.URL) string {
+	if u.EscapedPath() == "" {
+		return "/"
+	}
+	return u.EscapedPath()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,160 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+/*
+QueryClass - class of queries sharing one adapter
+*/
+type QueryClass string
+
+const (
+	// ClassInteractive - user-facing queries (default class)
+	ClassInteractive QueryClass = "interactive"
+	// ClassBatch - backfills, exports and other background queries
+	ClassBatch QueryClass = "batch"
+)
+
+/*
+ClassLimits - limits of query class
+— Concurrency: max queries of class running at once (0 - unlimited)
+— Timeout: query timeout (0 - no timeout). Requires adapter implementing ContextAdapter
+— Priority: classes with lower priority wait while higher priority queries are waiting for slot
+*/
+type ClassLimits struct {
+	Concurrency int
+	Timeout     time.Duration
+	Priority    int
+}
+
+// DefaultClassLimits - interactive queries always go first, batch queries are limited and get longer timeout
+var DefaultClassLimits = map[QueryClass]ClassLimits{
+	ClassInteractive: {Priority: 10, Timeout: 30 * time.Second},
+	ClassBatch:       {Priority: 0, Concurrency: 2, Timeout: 10 * time.Minute},
+}
+
+/*
+Classified - adapter that schedules queries by class.
+Queries are run as ClassInteractive, use Class() to get adapter for another class
+*/
+type Classified struct {
+	Adapter
+	class     QueryClass
+	scheduler *scheduler
+}
+
+/*
+NewClassified - classified adapter constructor. DefaultClassLimits are used if limits are nil
+*/
+func NewClassified(a Adapter, limits map[QueryClass]ClassLimits) *Classified {
+	if limits == nil {
+		limits = DefaultClassLimits
+	}
+	s := &scheduler{
+		limits:  limits,
+		running: make(map[QueryClass]int),
+		waiting: make(map[QueryClass]int),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return &Classified{Adapter: a, class: ClassInteractive, scheduler: s}
+}
+
+/*
+Class - adapter running queries as class (shares limits with parent)
+*/
+func (c *Classified) Class(class QueryClass) *Classified {
+	return &Classified{Adapter: c.Adapter, class: class, scheduler: c.scheduler}
+}
+
+/*
+Exec - executing query in class slot
+*/
+func (c *Classified) Exec(SQL string, args ...interface{}) (sql.Result, error) {
+	limits := c.scheduler.acquire(c.class)
+	defer c.scheduler.release(c.class)
+	if ca, ok := c.Adapter.(ContextAdapter); ok && limits.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), limits.Timeout)
+		defer cancel()
+		return ca.ExecContext(ctx, SQL, args...)
+	}
+	return c.Adapter.Exec(SQL, args...)
+}
+
+/*
+Query - executing query in class slot.
+Slot is released when query returned, reading rows is not limited
+*/
+func (c *Classified) Query(SQL string, args ...interface{}) (*sql.Rows, error) {
+	limits := c.scheduler.acquire(c.class)
+	defer c.scheduler.release(c.class)
+	if ca, ok := c.Adapter.(ContextAdapter); ok && limits.Timeout > 0 {
+		// rows are read after return, so context is cancelled by timer only
+		ctx, cancel := context.WithTimeout(context.Background(), limits.Timeout)
+		time.AfterFunc(limits.Timeout, cancel)
+		return ca.QueryContext(ctx, SQL, args...)
+	}
+	return c.Adapter.Query(SQL, args...)
+}
+
+/*
+QueryRow - executing single row query in class slot
+*/
+func (c *Classified) QueryRow(SQL string, args ...interface{}) (*sql.Row, error) {
+	c.scheduler.acquire(c.class)
+	defer c.scheduler.release(c.class)
+	return c.Adapter.QueryRow(SQL, args...)
+}
+
+/*
+ExecBatch - executing statements in class slot
+*/
+func (c *Classified) ExecBatch(statements []Statement) error {
+	c.scheduler.acquire(c.class)
+	defer c.scheduler.release(c.class)
+	return c.Adapter.ExecBatch(statements)
+}
+
+type scheduler struct {
+	limits  map[QueryClass]ClassLimits
+	mu      sync.Mutex
+	cond    *sync.Cond
+	running map[QueryClass]int
+	waiting map[QueryClass]int
+}
+
+func (s *scheduler) acquire(class QueryClass) ClassLimits {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waiting[class]++
+	for !s.canRun(class) {
+		s.cond.Wait()
+	}
+	s.waiting[class]--
+	s.running[class]++
+	return s.limits[class]
+}
+
+func (s *scheduler) release(class QueryClass) {
+	s.mu.Lock()
+	s.running[class]--
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+// canRun - class has free slot and there are no waiting queries with higher priority
+func (s *scheduler) canRun(class QueryClass) bool {
+	limits := s.limits[class]
+	if limits.Concurrency > 0 && s.running[class] >= limits.Concurrency {
+		return false
+	}
+	for other, n := range s.waiting {
+		if n > 0 && s.limits[other].Priority > limits.Priority {
+			return false
+		}
+	}
+	return true
+}
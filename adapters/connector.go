@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"time"
+)
+
+// defaultMaxIdleConns - database/sql default
+const defaultMaxIdleConns = 2
+
+// connector - opening every new connection with DSN built from current credentials
+type connector struct {
+	driver driver.Driver
+	dsn    func() (string, error)
+	open   func(string) (driver.Conn, error)
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := c.dsn()
+	if err != nil {
+		return nil, err
+	}
+	if c.open != nil {
+		return c.open(dsn)
+	}
+	return c.driver.Open(dsn)
+}
+
+func (c connector) Driver() driver.Driver {
+	return c.driver
+}
+
+/*
+openWithConnector - opening DB with password resolved by config.Secrets (if set) for every connection.
+open is used to open connection instead of driver (e.g. with custom dialer)
+*/
+func openWithConnector(driverName string, config Config, dsn func(string) string, open func(string) (driver.Conn, error)) (*sql.DB, error) {
+	// sql.Open doesn't connect, used only to get registered driver
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+	return sql.OpenDB(connector{
+		driver: drv,
+		open:   open,
+		dsn: func() (string, error) {
+			if config.Secrets == nil {
+				return dsn(config.Password), nil
+			}
+			password, err := config.Secrets.Secret(config.PasswordKey)
+			if err != nil {
+				return "", err
+			}
+			return dsn(password), nil
+		},
+	}), nil
+}
+
+// maxIdleConns - idle connections of config
+func maxIdleConns(config Config) int {
+	if config.MaxIdleConns == 0 {
+		return defaultMaxIdleConns
+	}
+	return config.MaxIdleConns
+}
+
+// rotateIdle - closing idle connections and restoring configured limit of them
+func rotateIdle(conn *sql.DB, config Config) {
+	conn.SetMaxIdleConns(0)
+	conn.SetMaxIdleConns(maxIdleConns(config))
+}
+
+func watchSecret(config Config, interval time.Duration, rotate func()) (stop func()) {
+	done := make(chan struct{})
+	if config.Secrets == nil {
+		return func() {}
+	}
+	go func() {
+		current, _ := config.Secrets.Secret(config.PasswordKey)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				secret, err := config.Secrets.Secret(config.PasswordKey)
+				if err != nil {
+					log.Println("Secret watch error: ", err)
+					continue
+				}
+				if secret != current {
+					current = secret
+					log.Println("Credentials changed, rotating connections")
+					rotate()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,38 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestRotateIdleKeepsLimit(t *testing.T) {
+	db := newFakeAdapter(t, fakeResult{}).db
+	config := Config{MaxIdleConns: 5}
+	db.SetMaxIdleConns(maxIdleConns(config))
+	hold := func() {
+		var conns []*sql.Conn
+		for i := 0; i < 5; i++ {
+			c, err := db.Conn(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			conns = append(conns, c)
+		}
+		for _, c := range conns {
+			c.Close()
+		}
+	}
+	hold()
+	rotateIdle(db, config)
+	if idle := db.Stats().Idle; idle != 0 {
+		t.Fatalf("%d idle connections after rotate", idle)
+	}
+	hold()
+	if idle := db.Stats().Idle; idle != 5 {
+		t.Fatalf("%d idle connections, want configured 5", idle)
+	}
+	if n := maxIdleConns(Config{}); n != defaultMaxIdleConns {
+		t.Fatalf("default idle connections %d", n)
+	}
+}
@@ -0,0 +1,47 @@
+package adapters
+
+import (
+	"context"
+	"io"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+/*
+Copier - adapter streaming query results in bulk format (Postgres COPY TO STDOUT)
+*/
+type Copier interface {
+	CopyTo(w io.Writer, SQL string) (int64, error)
+}
+
+/*
+CopyTo - running COPY ... TO STDOUT statement and writing its output into w.
+lib/pq doesn't support COPY TO, so statement is run over dedicated connection.
+Returns number of copied rows
+*/
+func (psql *Postgres) CopyTo(w io.Writer, SQL string) (int64, error) {
+	ctx := context.Background()
+	password, err := psql.password()
+	if err != nil {
+		return 0, err
+	}
+	config, err := pgconn.ParseConfig(psql.dsn(password))
+	if err != nil {
+		return 0, err
+	}
+	if psql.Config.TLS != nil {
+		if config.TLSConfig, err = psql.Config.TLS.Build(psql.Config.Host); err != nil {
+			return 0, err
+		}
+	}
+	conn, err := pgconn.ConnectConfig(ctx, config)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close(ctx)
+	tag, err := conn.CopyTo(ctx, w, SQL)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
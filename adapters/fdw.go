@@ -0,0 +1,73 @@
+package adapters
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+ForeignServer - remote Postgres server for postgres_fdw.
+LocalUser is role mapped to remote User (CURRENT_USER by default)
+*/
+type ForeignServer struct {
+	Name      string
+	Host      string
+	Port      int
+	Database  string
+	User      string
+	Password  string
+	LocalUser string
+}
+
+/*
+EnsureForeignServer - creating postgres_fdw extension, server and user mapping,
+or refreshing options (host, credentials) of existing ones. Foreign tables could be used as
+repository source then: repositories.NewPostgres(adapter, "remote.users", &User{})
+*/
+func EnsureForeignServer(a Adapter, s ForeignServer) error {
+	local := "CURRENT_USER"
+	if s.LocalUser != "" {
+		local = quoteIdent(s.LocalUser)
+	}
+	server := quoteIdent(s.Name)
+	serverOptions := "host " + quoteLiteral(s.Host) + ", port " + quoteLiteral(strconv.Itoa(s.Port)) + ", dbname " + quoteLiteral(s.Database)
+	userOptions := "user " + quoteLiteral(s.User) + ", password " + quoteLiteral(s.Password)
+	return a.ExecBatch([]Statement{
+		{SQL: "CREATE EXTENSION IF NOT EXISTS postgres_fdw"},
+		{SQL: "CREATE SERVER IF NOT EXISTS " + server + " FOREIGN DATA WRAPPER postgres_fdw OPTIONS (" + serverOptions + ")"},
+		{SQL: "ALTER SERVER " + server + " OPTIONS (SET " + strings.Replace(serverOptions, ", ", ", SET ", -1) + ")"},
+		{SQL: "CREATE USER MAPPING IF NOT EXISTS FOR " + local + " SERVER " + server + " OPTIONS (" + userOptions + ")"},
+		{SQL: "ALTER USER MAPPING FOR " + local + " SERVER " + server + " OPTIONS (SET " + strings.Replace(userOptions, ", ", ", SET ", -1) + ")"},
+	})
+}
+
+/*
+ImportForeignTables - (re)creating foreign tables of remote schema in local schema.
+Listed tables are dropped first, so remote schema changes are picked up.
+Empty tables imports whole remote schema (local schema must not have them yet)
+*/
+func ImportForeignTables(a Adapter, server, remoteSchema, localSchema string, tables []string) error {
+	var statements []Statement
+	statements = append(statements, Statement{SQL: "CREATE SCHEMA IF NOT EXISTS " + quoteIdent(localSchema)})
+	var limit []string
+	for _, t := range tables {
+		statements = append(statements, Statement{SQL: "DROP FOREIGN TABLE IF EXISTS " + quoteIdent(localSchema) + "." + quoteIdent(t)})
+		limit = append(limit, quoteIdent(t))
+	}
+	SQL := "IMPORT FOREIGN SCHEMA " + quoteIdent(remoteSchema)
+	if len(limit) > 0 {
+		SQL += " LIMIT TO (" + strings.Join(limit, ", ") + ")"
+	}
+	SQL += " FROM SERVER " + quoteIdent(server) + " INTO " + quoteIdent(localSchema)
+	return a.ExecBatch(append(statements, Statement{SQL: SQL}))
+}
+
+// quoteIdent - quoting identifier for DDL statements that don't accept parameters
+func quoteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// quoteLiteral - quoting string literal for DDL statements that don't accept parameters
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,87 @@
+package adapters
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+/*
+QueryScalar - executing query and returning first column of first row.
+Returns sql.ErrNoRows if query returned nothing
+*/
+func QueryScalar(a Adapter, SQL string, args ...interface{}) (interface{}, error) {
+	rows, err := a.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	// columns are read before scanning, rows read to the end are closed
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, errors.New("QueryScalar: query returned no columns")
+	}
+	items, err := ScanMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return items[0][cols[0]], nil
+}
+
+/*
+QueryColumn - executing query and scanning first column of every row into dest.
+dest is a pointer to a typed slice, e.g. *[]int64 or *[]string
+*/
+func QueryColumn(a Adapter, dest interface{}, SQL string, args ...interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("QueryColumn: dest must be a pointer to slice")
+	}
+	slice := rv.Elem()
+	rows, err := a.Query(SQL, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item := reflect.New(slice.Type().Elem())
+		if err := rows.Scan(item.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+	return rows.Err()
+}
+
+/*
+QueryMapRows - executing query and returning rows as maps (see ScanMaps)
+*/
+func QueryMapRows(a Adapter, SQL string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := a.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return ScanMaps(rows)
+}
+
+// execInTx - executing statements in transaction, rolling back on first error
+func execInTx(conn *sql.DB, statements []Statement) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	for _, st := range statements {
+		if _, err := tx.Exec(st.SQL, st.Args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+// fakeResult - columns and rows returned by fake driver for every query
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var (
+	fakeResults sync.Map
+	fakeSeq     int64
+	fakeOnce    sync.Once
+)
+
+// fakeAdapter - adapter over database/sql with driver returning fixed result
+type fakeAdapter struct {
+	db *sql.DB
+}
+
+func newFakeAdapter(t *testing.T, result fakeResult) *fakeAdapter {
+	fakeOnce.Do(func() { sql.Register("vodkafake-adapters", fakeDriver{}) })
+	dsn := fmt.Sprint("fake", atomic.AddInt64(&fakeSeq, 1))
+	fakeResults.Store(dsn, result)
+	db, err := sql.Open("vodkafake-adapters", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &fakeAdapter{db: db}
+}
+
+func (a *fakeAdapter) Connect() error { return nil }
+func (a *fakeAdapter) Exec(q string, args ...interface{}) (sql.Result, error) {
+	return a.db.Exec(q, args...)
+}
+func (a *fakeAdapter) QueryRow(q string, args ...interface{}) (*sql.Row, error) {
+	return a.db.QueryRow(q, args...), nil
+}
+func (a *fakeAdapter) Query(q string, args ...interface{}) (*sql.Rows, error) {
+	return a.db.Query(q, args...)
+}
+func (a *fakeAdapter) ExecBatch(statements []Statement) error { return nil }
+func (a *fakeAdapter) Begin() (*sql.Tx, error)                { return a.db.Begin() }
+func (a *fakeAdapter) Builder() builders.Builder              { return builders.NewPostgres() }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	result, ok := fakeResults.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown dsn %s", dsn)
+	}
+	return &fakeConn{result: result.(fakeResult)}, nil
+}
+
+type fakeConn struct {
+	result fakeResult
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{conn: c}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c *fakeConn) Commit() error                             { return nil }
+func (c *fakeConn) Rollback() error                           { return nil }
+
+type fakeStmt struct {
+	conn *fakeConn
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{result: s.conn.result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	i      int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.i])
+	r.i++
+	return nil
+}
+
+func TestQueryScalar(t *testing.T) {
+	a := newFakeAdapter(t, fakeResult{columns: []string{"n", "m"}, rows: [][]driver.Value{{int64(3), int64(4)}, {int64(5), int64(6)}}})
+	v, err := QueryScalar(a, "SELECT 3 AS n, 4 AS m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != int64(3) {
+		t.Errorf("QueryScalar = %#v, want 3", v)
+	}
+}
+
+func TestQueryScalarNoRows(t *testing.T) {
+	a := newFakeAdapter(t, fakeResult{columns: []string{"n"}})
+	if _, err := QueryScalar(a, "SELECT n FROM t"); err != sql.ErrNoRows {
+		t.Errorf("QueryScalar error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestQueryColumn(t *testing.T) {
+	a := newFakeAdapter(t, fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}})
+	var ids []int64
+	if err := QueryColumn(a, &ids, "SELECT id FROM t"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("QueryColumn = %v", ids)
+	}
+	if err := QueryColumn(a, ids, "SELECT id FROM t"); err == nil {
+		t.Error("QueryColumn accepted non-pointer dest")
+	}
+}
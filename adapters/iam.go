@@ -0,0 +1,89 @@
+package adapters
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	rdsTokenTTL     = 15 * time.Minute
+	gcpMetadataURL  = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcpTokenReserve = time.Minute
+)
+
+/*
+RDSIAMAuth - AWS RDS IAM authentication. Generates short-lived auth token
+(valid 15 minutes) for every new connection, so static password is not needed.
+Use as Config.Secrets; RDS requires SSL, so SSLmode has to be at least "require"
+*/
+type RDSIAMAuth struct {
+	Host        string
+	Port        int
+	User        string
+	Region      string
+	Credentials AWSCredentials
+}
+
+/*
+NewRDSIAMAuth - RDS IAM auth for config host, port and user.
+Credentials are read from AWS_* environment variables
+*/
+func NewRDSIAMAuth(config Config, region string) RDSIAMAuth {
+	return RDSIAMAuth{
+		Host:        config.Host,
+		Port:        config.Port,
+		User:        config.User,
+		Region:      region,
+		Credentials: AWSCredentialsFromEnv(),
+	}
+}
+
+// Secret - generating auth token (key is ignored)
+func (r RDSIAMAuth) Secret(key string) (string, error) {
+	u := &url.URL{
+		Host:     r.Host + ":" + strconv.Itoa(r.Port),
+		Path:     "/",
+		RawQuery: url.Values{"Action": {"connect"}, "DBUser": {r.User}}.Encode(),
+	}
+	return u.Host + "/?" + presignV4(u, r.Credentials, r.Region, "rds-db", time.Now(), rdsTokenTTL), nil
+}
+
+/*
+GCPIAMAuth - Google Cloud SQL IAM database authentication.
+OAuth2 access token of instance service account (from metadata server) is used as password.
+Token is cached until it is about to expire. Use as Config.Secrets
+*/
+type GCPIAMAuth struct {
+	Client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Secret - returning access token (key is ignored)
+func (g *GCPIAMAuth) Secret(key string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Now().Add(gcpTokenReserve).Before(g.expires) {
+		return g.token, nil
+	}
+	req, err := http.NewRequest("GET", gcpMetadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doSecretRequest(g.Client, req, &resp); err != nil {
+		return "", err
+	}
+	g.token = resp.AccessToken
+	g.expires = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	return g.token, nil
+}
@@ -0,0 +1,59 @@
+package adapters
+
+import (
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+/*
+Listener - adapter receiving notifications from other database clients (Postgres LISTEN/NOTIFY)
+*/
+type Listener interface {
+	Listen(channel string) (<-chan string, error)
+}
+
+/*
+Listen - receiving payloads of NOTIFY on channel over dedicated connection.
+Connection is reestablished on failures, empty payload is sent after reconnect
+because notifications could be lost while it was down
+*/
+func (psql *Postgres) Listen(channel string) (<-chan string, error) {
+	password, err := psql.password()
+	if err != nil {
+		return nil, err
+	}
+	onEvent := func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("Postgres listener ("+channel+"): ", err)
+		}
+	}
+	var l *pq.Listener
+	if psql.Config.TLS != nil {
+		tlsConfig, err := psql.Config.TLS.Build(psql.Config.Host)
+		if err != nil {
+			return nil, err
+		}
+		l = pq.NewDialListener(postgresTLSDialer{config: tlsConfig}, psql.dsn(password), time.Second, time.Minute, onEvent)
+	} else {
+		l = pq.NewListener(psql.dsn(password), time.Second, time.Minute, onEvent)
+	}
+	if err := l.Listen(channel); err != nil {
+		l.Close()
+		return nil, err
+	}
+	payloads := make(chan string, 64)
+	go func() {
+		defer close(payloads)
+		for n := range l.Notify {
+			if n == nil {
+				// reconnected
+				payloads <- ""
+				continue
+			}
+			payloads <- n.Extra
+		}
+	}()
+	return payloads, nil
+}
@@ -0,0 +1,110 @@
+package adapters
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// pqLockNotAvailable - lock wait timed out (lock_timeout) or NOWAIT failed
+	pqLockNotAvailable = "55P03"
+	// pqDeadlockDetected - transaction was aborted to resolve deadlock
+	pqDeadlockDetected = "40P01"
+	// maxBlockers - max number of sessions reported in LockError
+	maxBlockers = 10
+)
+
+/*
+Blocker - session holding locks when statement failed to get its lock:
+session blocking others or idle in open transaction (forgotten COMMIT)
+*/
+type Blocker struct {
+	PID         int     `json:"pid"`
+	User        string  `json:"user"`
+	Application string  `json:"application"`
+	State       string  `json:"state"`
+	Query       string  `json:"query"`
+	Transaction float64 `json:"transaction"` // seconds since transaction start
+	Blocking    int     `json:"blocking"`    // number of sessions waiting for it
+}
+
+/*
+LockError - lock wait timeout or deadlock with sessions that were holding locks at that moment.
+Original error is available with errors.As/errors.Is
+*/
+type LockError struct {
+	Err      error
+	Blockers []Blocker
+}
+
+func (e *LockError) Error() string {
+	if len(e.Blockers) == 0 {
+		return e.Err.Error()
+	}
+	var sessions []string
+	for _, b := range e.Blockers {
+		sessions = append(sessions, fmt.Sprintf("pid %d (%s, %s, %.1fs in transaction, blocking %d): %s",
+			b.PID, b.Application, b.State, b.Transaction, b.Blocking, b.Query))
+	}
+	return e.Err.Error() + "; blocked by " + strings.Join(sessions, "; ")
+}
+
+// Unwrap - original driver error
+func (e *LockError) Unwrap() error {
+	return e.Err
+}
+
+// blockersSQL - sessions blocking others and sessions idle in transaction, longest transactions first
+const blockersSQL = `SELECT a.pid, coalesce(a.usename, ''), a.application_name, coalesce(a.state, ''), a.query,
+	coalesce(EXTRACT(EPOCH FROM now() - a.xact_start), 0),
+	(SELECT count(*) FROM pg_stat_activity w WHERE a.pid = ANY(pg_blocking_pids(w.pid)))
+FROM pg_stat_activity a
+WHERE a.pid <> pg_backend_pid() AND a.xact_start IS NOT NULL
+	AND (a.state LIKE 'idle in transaction%' OR EXISTS (
+		SELECT 1 FROM pg_stat_activity w WHERE a.pid = ANY(pg_blocking_pids(w.pid))))
+ORDER BY a.xact_start
+LIMIT $1`
+
+/*
+diagnoseLocks - wrapping lock wait timeout and deadlock errors into LockError
+with blocking sessions (pg_stat_activity, pg_blocking_pids). Other errors are returned as is
+*/
+func diagnoseLocks(conn *sql.DB, err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	if pqErr.Code != pqLockNotAvailable && pqErr.Code != pqDeadlockDetected {
+		return err
+	}
+	lockErr := &LockError{Err: err}
+	rows, qErr := conn.Query(blockersSQL, maxBlockers)
+	if qErr != nil {
+		// diagnostics are best effort, original error matters
+		return lockErr
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b Blocker
+		if rows.Scan(&b.PID, &b.User, &b.Application, &b.State, &b.Query, &b.Transaction, &b.Blocking) != nil {
+			break
+		}
+		lockErr.Blockers = append(lockErr.Blockers, b)
+	}
+	return lockErr
+}
+
+/*
+DiagnoseLocks - reporting blocking sessions for errors of statements run outside of adapter
+methods (transactions from Begin, rows.Err). Returns *LockError for lock errors, err otherwise
+*/
+func (psql *Postgres) DiagnoseLocks(err error) error {
+	if err == nil || psql.conn == nil {
+		return err
+	}
+	return diagnoseLocks(psql.conn, err)
+}
@@ -1,15 +1,21 @@
 package adapters
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"strings"
+	"sync/atomic"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql" // MySQL driver
+	"github.com/go-sql-driver/mysql" // MySQL driver
 	"github.com/niklucky/vodka/builders"
 )
 
+// mysqlTLSSeq - counter of TLS config names registered in MySQL driver, one per adapter
+var mysqlTLSSeq int64
+
 /*
 MySQL - low-level Postgres adapters for DataServices
 */
@@ -18,6 +24,7 @@ type MySQL struct {
 	config         Config
 	conn           *sql.DB
 	connectionInfo string
+	tlsKey         string // name of TLS config of adapter registered in driver
 }
 
 /*
@@ -42,40 +49,79 @@ func (db *MySQL) Connect() error {
 Builder - returns Query builder (SQL) instance
 */
 func (db MySQL) Builder() builders.Builder {
-	return builders.NewPostgres()
+	return dialectBuilder(db.config, "mysql")
 }
 
 /*
 Exec - executing SQL-query and returning *Rows
 */
-func (db *MySQL) Exec(SQL string) (res sql.Result, err error) {
+func (db *MySQL) Exec(SQL string, args ...interface{}) (res sql.Result, err error) {
 	if err = db.checkConnection(); err != nil {
 		return
 	}
-	res, err = db.conn.Exec(SQL)
+	res, err = db.conn.Exec(SQL, args...)
 	if err != nil {
 		if isInvalidConnection(err) {
 			db.closeConnection()
-			return db.Exec(SQL)
+			return db.Exec(SQL, args...)
 		}
 	}
 	return
 }
 
+/*
+ExecBatch - executing statements in transaction
+(multi-statement queries are disabled in MySQL driver by default)
+*/
+func (db *MySQL) ExecBatch(statements []Statement) error {
+	if err := db.checkConnection(); err != nil {
+		return err
+	}
+	return execInTx(db.conn, statements)
+}
+
+/*
+Begin - starting transaction
+*/
+func (db *MySQL) Begin() (*sql.Tx, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.Begin()
+}
+
+/*
+ExecContext - executing SQL-query with context (cancellation, timeouts)
+*/
+func (db *MySQL) ExecContext(ctx context.Context, SQL string, args ...interface{}) (sql.Result, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.ExecContext(ctx, SQL, args...)
+}
+
+/*
+QueryContext - executing SQL-query with context (cancellation, timeouts)
+*/
+func (db *MySQL) QueryContext(ctx context.Context, SQL string, args ...interface{}) (*sql.Rows, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.QueryContext(ctx, SQL, args...)
+}
+
 /*
 Query - preparing query into Statement and executing SQL-query and returning *Rows
 */
-func (db *MySQL) Query(v ...interface{}) (rows *sql.Rows, err error) {
+func (db *MySQL) Query(SQL string, args ...interface{}) (rows *sql.Rows, err error) {
 	if err = db.checkConnection(); err != nil {
 		return
 	}
-	SQL := v[0].(string)
-	values := v[1:]
-	rows, err = db.conn.Query(SQL, values...)
+	rows, err = db.conn.Query(SQL, args...)
 	if err != nil {
 		if isInvalidConnection(err) {
 			db.closeConnection()
-			return db.Query(v...)
+			return db.Query(SQL, args...)
 		}
 	}
 	return
@@ -84,15 +130,15 @@ func (db *MySQL) Query(v ...interface{}) (rows *sql.Rows, err error) {
 /*
 QueryRow - executing single row query. May be suitable for INSERT/UPDATE.
 */
-func (db *MySQL) QueryRow(SQL string) (row *sql.Row, err error) {
+func (db *MySQL) QueryRow(SQL string, args ...interface{}) (row *sql.Row, err error) {
 	if err := db.checkConnection(); err != nil {
 		return nil, err
 	}
-	row, err = db.conn.QueryRow(SQL), nil
+	row, err = db.conn.QueryRow(SQL, args...), nil
 	if err != nil {
 		if isInvalidConnection(err) {
 			db.closeConnection()
-			return db.QueryRow(SQL)
+			return db.QueryRow(SQL, args...)
 		}
 	}
 	return
@@ -103,27 +149,81 @@ func isInvalidConnection(err error) bool {
 }
 
 func (db *MySQL) connect() error {
-	config := db.config
-	db.connectionInfo = fmt.Sprintf("%s:%s@tcp(%s:%v)/%v?charset=utf8mb4,utf8",
-		config.User,
-		config.Password,
-		config.Host,
-		config.Port,
-		config.Database,
-	)
-	log.Println("Connecting to MySQL: ", db.connectionInfo)
-	conn, err := sql.Open(db.driverName, db.connectionInfo)
+	var conn *sql.DB
+	var err error
+	if err = checkDialect(db.config); err != nil {
+		return err
+	}
+	if db.config.TLS != nil {
+		tlsConfig, err := db.config.TLS.Build(db.config.Host)
+		if err != nil {
+			return err
+		}
+		if db.tlsKey == "" {
+			db.tlsKey = fmt.Sprint("vodka", atomic.AddInt64(&mysqlTLSSeq, 1))
+		}
+		if err = mysql.RegisterTLSConfig(db.tlsKey, tlsConfig); err != nil {
+			return err
+		}
+	}
+	db.connectionInfo = db.dsn(db.config.Password)
+	if db.config.Secrets != nil {
+		log.Println("Connecting to MySQL: ", db.dsn("***"))
+		conn, err = openWithConnector(db.driverName, db.config, db.dsn, nil)
+	} else {
+		log.Println("Connecting to MySQL: ", db.connectionInfo)
+		conn, err = sql.Open(db.driverName, db.connectionInfo)
+	}
 	if err != nil {
 		fmt.Println("MySQL connection error", err)
 		return err
 	}
 	if conn == nil {
 		fmt.Println("Connection to MySQL is nil")
+	} else {
+		conn.SetMaxIdleConns(maxIdleConns(db.config))
 	}
 	db.conn = conn
 	return nil
 }
 
+// dsn - connection string with password
+func (db *MySQL) dsn(password string) string {
+	config := db.config
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%v)/%v?charset=utf8mb4,utf8",
+		config.User,
+		password,
+		config.Host,
+		config.Port,
+		config.Database,
+	)
+	if config.TLS != nil {
+		dsn += "&tls=" + db.tlsKey
+	}
+	if config.ReadOnly {
+		// unknown params are set by driver as session system variables
+		dsn += "&transaction_read_only=1"
+	}
+	return dsn
+}
+
+/*
+Rotate - closing idle connections, so new ones are opened with current credentials
+*/
+func (db *MySQL) Rotate() {
+	if db.conn != nil {
+		rotateIdle(db.conn, db.config)
+	}
+}
+
+/*
+WatchSecrets - checking password in config.Secrets every interval and rotating
+connections when it changes. Returns function that stops watching
+*/
+func (db *MySQL) WatchSecrets(interval time.Duration) (stop func()) {
+	return watchSecret(db.config, interval, db.Rotate)
+}
+
 func (db *MySQL) checkConnection() error {
 	if db.conn == nil {
 		return db.connect()
@@ -141,3 +241,21 @@ func (db *MySQL) closeConnection() error {
 	}
 	return nil
 }
+
+/*
+VerifyConnection - checking connection and reporting negotiated TLS state
+*/
+func (db *MySQL) VerifyConnection() (state TLSState, err error) {
+	if err = db.checkConnection(); err != nil {
+		return
+	}
+	var name string
+	if err = db.conn.QueryRow("SHOW SESSION STATUS LIKE 'Ssl_version'").Scan(&name, &state.Version); err != nil {
+		return
+	}
+	if err = db.conn.QueryRow("SHOW SESSION STATUS LIKE 'Ssl_cipher'").Scan(&name, &state.Cipher); err != nil {
+		return
+	}
+	state.Enabled = state.Cipher != ""
+	return
+}
@@ -0,0 +1,25 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMySQLTLSKeyPerAdapter(t *testing.T) {
+	a := NewMySQL(Config{Host: "a.example", Port: 3306, Database: "app", TLS: &TLSConfig{}})
+	b := NewMySQL(Config{Host: "b.example", Port: 3306, Database: "app", TLS: &TLSConfig{}})
+	// driver isn't required, TLS config is registered before opening
+	a.connect()
+	b.connect()
+	if a.tlsKey == "" || a.tlsKey == b.tlsKey {
+		t.Fatalf("TLS keys %q and %q", a.tlsKey, b.tlsKey)
+	}
+	if !strings.Contains(a.dsn(""), "&tls="+a.tlsKey) || !strings.Contains(b.dsn(""), "&tls="+b.tlsKey) {
+		t.Fatalf("dsn %q, %q", a.dsn(""), b.dsn(""))
+	}
+	key := a.tlsKey
+	a.connect()
+	if a.tlsKey != key {
+		t.Fatalf("reconnect changed TLS key %q -> %q", key, a.tlsKey)
+	}
+}
@@ -1,11 +1,17 @@
 package adapters
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
+	"net/url"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 	"github.com/niklucky/vodka/builders"
 )
 
@@ -42,67 +48,193 @@ func (psql *Postgres) Connect() error {
 Builder - returns Query builder (SQL) instance
 */
 func (psql Postgres) Builder() builders.Builder {
-	return builders.NewPostgres()
+	return dialectBuilder(psql.Config, "postgres")
 }
 
 /*
-Exec - executing SQL-query and returning *Rows
+Exec - executing SQL-query and returning *Rows.
+Lock wait timeouts and deadlocks are returned as *LockError with blocking sessions
 */
-func (psql *Postgres) Exec(SQL string) (sql.Result, error) {
+func (psql *Postgres) Exec(SQL string, args ...interface{}) (sql.Result, error) {
 	if err := psql.checkConnection(); err != nil {
 		return nil, err
 	}
-	return psql.conn.Exec(SQL)
+	result, err := psql.conn.Exec(SQL, args...)
+	if err != nil {
+		return nil, diagnoseLocks(psql.conn, err)
+	}
+	return result, nil
+}
+
+/*
+ExecBatch - executing statements atomically in one round trip.
+Statements without arguments are sent as single multi-statement query
+(Postgres runs it in implicit transaction), otherwise they are executed in transaction
+*/
+func (psql *Postgres) ExecBatch(statements []Statement) error {
+	if err := psql.checkConnection(); err != nil {
+		return err
+	}
+	var queries []string
+	for _, st := range statements {
+		if len(st.Args) > 0 {
+			return execInTx(psql.conn, statements)
+		}
+		queries = append(queries, st.SQL)
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+	_, err := psql.conn.Exec(strings.Join(queries, ";\n"))
+	return err
+}
+
+/*
+Begin - starting transaction
+*/
+func (psql *Postgres) Begin() (*sql.Tx, error) {
+	if err := psql.checkConnection(); err != nil {
+		return nil, err
+	}
+	return psql.conn.Begin()
+}
+
+/*
+ExecContext - executing SQL-query with context (cancellation, timeouts)
+*/
+func (psql *Postgres) ExecContext(ctx context.Context, SQL string, args ...interface{}) (sql.Result, error) {
+	if err := psql.checkConnection(); err != nil {
+		return nil, err
+	}
+	result, err := psql.conn.ExecContext(ctx, SQL, args...)
+	if err != nil {
+		return nil, diagnoseLocks(psql.conn, err)
+	}
+	return result, nil
 }
 
 /*
-Query - preparing query into Statement and executing SQL-query and returning *Rows
+QueryContext - executing SQL-query with context (cancellation, timeouts)
 */
-func (psql *Postgres) Query(v ...interface{}) (*sql.Rows, error) {
+func (psql *Postgres) QueryContext(ctx context.Context, SQL string, args ...interface{}) (*sql.Rows, error) {
 	if err := psql.checkConnection(); err != nil {
 		return nil, err
 	}
-	SQL := v[0].(string)
-	values := v[1:]
-	return psql.conn.Query(SQL, values...)
+	rows, err := psql.conn.QueryContext(ctx, SQL, args...)
+	if err != nil {
+		return nil, diagnoseLocks(psql.conn, err)
+	}
+	return rows, nil
+}
+
+/*
+Query - preparing query into Statement and executing SQL-query and returning *Rows.
+Lock errors are reported as in Exec
+*/
+func (psql *Postgres) Query(SQL string, args ...interface{}) (*sql.Rows, error) {
+	if err := psql.checkConnection(); err != nil {
+		return nil, err
+	}
+	rows, err := psql.conn.Query(SQL, args...)
+	if err != nil {
+		return nil, diagnoseLocks(psql.conn, err)
+	}
+	return rows, nil
 }
 
 /*
 QueryRow - executing single row query. May be suitable for INSERT/UPDATE.
 */
-func (psql *Postgres) QueryRow(SQL string) (*sql.Row, error) {
+func (psql *Postgres) QueryRow(SQL string, args ...interface{}) (*sql.Row, error) {
 	if err := psql.checkConnection(); err != nil {
 		return nil, err
 	}
-	return psql.conn.QueryRow(SQL), nil
+	return psql.conn.QueryRow(SQL, args...), nil
 }
 
 func (psql *Postgres) connect() error {
-	config := psql.Config
-	if config.SSLmode == "" {
-		config.SSLmode = "disable"
+	var conn *sql.DB
+	var err error
+	if err = checkDialect(psql.Config); err != nil {
+		return err
+	}
+	psql.connectionInfo = psql.dsn(psql.Config.Password)
+	if psql.Config.TLS != nil {
+		log.Println("Connecting to Postgres (TLS): ", psql.dsn("***"))
+		var tlsConfig *tls.Config
+		if tlsConfig, err = psql.Config.TLS.Build(psql.Config.Host); err != nil {
+			return err
+		}
+		conn, err = openWithConnector(driverName, psql.Config, psql.dsn, func(dsn string) (driver.Conn, error) {
+			return pq.DialOpen(postgresTLSDialer{config: tlsConfig}, dsn)
+		})
+	} else if psql.Config.Secrets != nil {
+		log.Println("Connecting to Postgres: ", psql.dsn("***"))
+		conn, err = openWithConnector(driverName, psql.Config, psql.dsn, nil)
+	} else {
+		log.Println("Connecting to Postgres: ", psql.connectionInfo)
+		conn, err = sql.Open(driverName, psql.connectionInfo)
 	}
-	psql.connectionInfo = fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=%v",
-		config.User,
-		config.Password,
-		config.Host,
-		config.Port,
-		config.Database,
-		config.SSLmode,
-	)
-	log.Println("Connecting to Postgres: ", psql.connectionInfo)
-	conn, err := sql.Open("postgres", psql.connectionInfo)
 	if err != nil {
 		fmt.Println("Postgres connection error", err)
 		return err
 	}
 	if conn == nil {
 		fmt.Println("Connection to postgres is nil")
+	} else {
+		conn.SetMaxIdleConns(maxIdleConns(psql.Config))
 	}
 	psql.conn = conn
 	return nil
 }
 
+// dsn - connection string with password
+func (psql *Postgres) dsn(password string) string {
+	config := psql.Config
+	if config.SSLmode == "" || config.TLS != nil {
+		// with TLS config handshake is done by dialer
+		config.SSLmode = "disable"
+	}
+	dsn := fmt.Sprintf("postgres://%v@%v:%v/%v?sslmode=%v",
+		url.UserPassword(config.User, password),
+		config.Host,
+		config.Port,
+		config.Database,
+		config.SSLmode,
+	)
+	if config.ReadOnly {
+		// unknown params are sent by driver as session run-time parameters
+		dsn += "&default_transaction_read_only=on"
+	}
+	return dsn
+}
+
+// password - current password (from Config.Secrets if set) for dedicated connections
+func (psql *Postgres) password() (string, error) {
+	if psql.Config.Secrets == nil {
+		return psql.Config.Password, nil
+	}
+	return psql.Config.Secrets.Secret(psql.Config.PasswordKey)
+}
+
+/*
+Rotate - closing idle connections, so new ones are opened with current credentials.
+Connections in use are kept until released
+*/
+func (psql *Postgres) Rotate() {
+	if psql.conn != nil {
+		rotateIdle(psql.conn, psql.Config)
+	}
+}
+
+/*
+WatchSecrets - checking password in Config.Secrets every interval and rotating
+connections when it changes. Returns function that stops watching
+*/
+func (psql *Postgres) WatchSecrets(interval time.Duration) (stop func()) {
+	return watchSecret(psql.Config, interval, psql.Rotate)
+}
+
 func (psql *Postgres) checkConnection() error {
 	fmt.Printf("Connection: %+v\n", psql.conn)
 	if psql.conn == nil {
@@ -110,3 +242,20 @@ func (psql *Postgres) checkConnection() error {
 	}
 	return nil
 }
+
+/*
+VerifyConnection - checking connection and reporting negotiated TLS state
+*/
+func (psql *Postgres) VerifyConnection() (state TLSState, err error) {
+	if err = psql.checkConnection(); err != nil {
+		return
+	}
+	var version, cipher sql.NullString
+	row := psql.conn.QueryRow("SELECT ssl, version, cipher FROM pg_stat_ssl WHERE pid = pg_backend_pid()")
+	if err = row.Scan(&state.Enabled, &version, &cipher); err != nil {
+		return
+	}
+	state.Version = version.String
+	state.Cipher = cipher.String
+	return
+}
@@ -0,0 +1,74 @@
+package adapters
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+/*
+ScanMaps - scanning rows into maps with values converted to Go types
+by database column type (rows.ColumnTypes)
+*/
+func ScanMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	dest := make([]interface{}, len(types))
+	raw := make([]interface{}, len(types))
+	for i := range types {
+		dest[i] = &raw[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		item := make(map[string]interface{}, len(types))
+		for i, t := range types {
+			item[t.Name()] = ConvertValue(t.DatabaseTypeName(), raw[i])
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+/*
+ConvertValue - converting raw driver value into Go type by database type name.
+Values that are already typed by driver are returned as is
+*/
+func ConvertValue(dbType string, v interface{}) interface{} {
+	b, ok := v.([]byte)
+	if !ok {
+		return v
+	}
+	s := string(b)
+	switch strings.ToUpper(dbType) {
+	case "INT", "INT2", "INT4", "INT8", "SMALLINT", "INTEGER", "BIGINT", "SERIAL", "BIGSERIAL", "TINYINT", "MEDIUMINT":
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case "FLOAT4", "FLOAT8", "NUMERIC", "DECIMAL", "REAL", "DOUBLE", "FLOAT", "MONEY":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case "BOOL", "BOOLEAN":
+		if bv, err := strconv.ParseBool(s); err == nil {
+			return bv
+		}
+	case "JSON", "JSONB":
+		var data interface{}
+		if err := json.Unmarshal(b, &data); err == nil {
+			return data
+		}
+	case "BYTEA", "BLOB", "BINARY", "VARBINARY":
+		return append([]byte(nil), b...)
+	}
+	if strings.HasPrefix(dbType, "_") {
+		// Postgres array types are named after element type: _TEXT, _INT8...
+		return convertArray(strings.ToUpper(dbType), s)
+	}
+	return s
+}
@@ -0,0 +1,168 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+SecretProvider - source of adapter credentials (passwords, tokens).
+Secret is resolved for every new connection, so rotated credentials are picked up automatically
+*/
+type SecretProvider interface {
+	Secret(key string) (string, error)
+}
+
+/*
+SecretFunc - function as SecretProvider
+*/
+type SecretFunc func(key string) (string, error)
+
+// Secret - calling function
+func (f SecretFunc) Secret(key string) (string, error) {
+	return f(key)
+}
+
+/*
+EnvSecrets - secrets from environment variables: key is a variable name
+*/
+type EnvSecrets struct{}
+
+// Secret - reading environment variable
+func (EnvSecrets) Secret(key string) (string, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("secret %s: environment variable is not set", key)
+	}
+	return v, nil
+}
+
+/*
+FileSecrets - secrets from files (Docker/Kubernetes secrets): key is a file name in Dir
+*/
+type FileSecrets struct {
+	Dir string
+}
+
+// Secret - reading file content (trailing new line is trimmed)
+func (f FileSecrets) Secret(key string) (string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(f.Dir, key))
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %v", key, err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+/*
+VaultSecrets - HashiCorp Vault KV (version 2) secrets.
+Key format is "path#field", e.g. "db/orders#password". Mount is "secret" by default
+*/
+type VaultSecrets struct {
+	Address string
+	Token   string
+	Mount   string
+	Client  *http.Client
+}
+
+// Secret - reading field of Vault secret
+func (v VaultSecrets) Secret(key string) (string, error) {
+	path, field := splitSecretKey(key)
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	req, err := http.NewRequest("GET", strings.TrimRight(v.Address, "/")+"/v1/"+mount+"/data/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := doSecretRequest(v.Client, req, &resp); err != nil {
+		return "", fmt.Errorf("secret %s: %v", key, err)
+	}
+	value, ok := resp.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s: field %s not found", key, field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+/*
+AWSSecrets - AWS Secrets Manager secrets.
+Key is a secret id, use "id#field" for JSON secrets (e.g. RDS generated "id#password")
+*/
+type AWSSecrets struct {
+	Region      string
+	Credentials AWSCredentials
+	Client      *http.Client
+}
+
+// Secret - fetching secret value with GetSecretValue
+func (a AWSSecrets) Secret(key string) (string, error) {
+	id, field := splitSecretKey(key)
+	body, _ := json.Marshal(map[string]string{"SecretId": id})
+	req, err := http.NewRequest("POST", "https://secretsmanager."+a.Region+".amazonaws.com/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, a.Credentials, a.Region, "secretsmanager", time.Now())
+	var resp struct {
+		SecretString string
+	}
+	if err := doSecretRequest(a.Client, req, &resp); err != nil {
+		return "", fmt.Errorf("secret %s: %v", key, err)
+	}
+	if field == "" {
+		return resp.SecretString, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(resp.SecretString), &data); err != nil {
+		return "", fmt.Errorf("secret %s: %v", key, err)
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s: field %s not found", key, field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// splitSecretKey - "path#field" -> path, field
+func splitSecretKey(key string) (path, field string) {
+	if i := strings.LastIndex(key, "#"); i != -1 {
+		return key[:i], key[i+1:]
+	}
+	return key, ""
+}
+
+func doSecretRequest(client *http.Client, req *http.Request, dest interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return errors.New(res.Status + ": " + string(b))
+	}
+	return json.Unmarshal(b, dest)
+}
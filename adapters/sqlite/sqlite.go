@@ -0,0 +1,184 @@
+/*
+Package sqlite - SQLite adapter. It is separate from adapters because driver requires cgo,
+so builds of other adapters don't depend on it
+*/
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+// sqliteMemory - name of in-memory SQLite database
+const sqliteMemory = ":memory:"
+
+/*
+SQLite - low-level SQLite adapter for DataServices (local and test deployments).
+Config.Database is path of database file or ":memory:", other connection params are ignored
+*/
+type SQLite struct {
+	config adapters.Config
+	conn   *sql.DB
+}
+
+/*
+New - adapter constructor
+*/
+func New(config adapters.Config) *SQLite {
+	return &SQLite{
+		config: config,
+	}
+}
+
+/*
+Connect - public method to connect.
+Not very useful because all methods checking connections and connecting by default
+*/
+func (db *SQLite) Connect() error {
+	return db.connect()
+}
+
+/*
+Builder - returns Query builder (SQL) instance
+*/
+func (db SQLite) Builder() builders.Builder {
+	name := db.config.Dialect
+	if name == "" {
+		name = "sqlite"
+	}
+	b, err := builders.New(name)
+	if err != nil {
+		// unknown dialect is reported by connect
+		b, _ = builders.New("sqlite")
+	}
+	return b
+}
+
+/*
+Exec - executing SQL-query
+*/
+func (db *SQLite) Exec(SQL string, args ...interface{}) (sql.Result, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.Exec(SQL, args...)
+}
+
+/*
+ExecBatch - executing statements in transaction
+*/
+func (db *SQLite) ExecBatch(statements []adapters.Statement) error {
+	if err := db.checkConnection(); err != nil {
+		return err
+	}
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	for _, st := range statements {
+		if _, err := tx.Exec(st.SQL, st.Args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/*
+Begin - starting transaction
+*/
+func (db *SQLite) Begin() (*sql.Tx, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.Begin()
+}
+
+/*
+ExecContext - executing SQL-query with context (cancellation, timeouts)
+*/
+func (db *SQLite) ExecContext(ctx context.Context, SQL string, args ...interface{}) (sql.Result, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.ExecContext(ctx, SQL, args...)
+}
+
+/*
+QueryContext - executing SQL-query with context (cancellation, timeouts)
+*/
+func (db *SQLite) QueryContext(ctx context.Context, SQL string, args ...interface{}) (*sql.Rows, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.QueryContext(ctx, SQL, args...)
+}
+
+/*
+Query - executing SQL-query and returning *Rows
+*/
+func (db *SQLite) Query(SQL string, args ...interface{}) (*sql.Rows, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.Query(SQL, args...)
+}
+
+/*
+QueryRow - executing single row query. May be suitable for INSERT/UPDATE.
+*/
+func (db *SQLite) QueryRow(SQL string, args ...interface{}) (*sql.Row, error) {
+	if err := db.checkConnection(); err != nil {
+		return nil, err
+	}
+	return db.conn.QueryRow(SQL, args...), nil
+}
+
+func (db *SQLite) connect() error {
+	if db.config.Dialect != "" {
+		if _, err := builders.New(db.config.Dialect); err != nil {
+			return err
+		}
+	}
+	log.Println("Connecting to SQLite: ", db.dsn())
+	conn, err := sql.Open("sqlite3", db.dsn())
+	if err != nil {
+		fmt.Println("SQLite connection error", err)
+		return err
+	}
+	if db.config.Database == sqliteMemory || db.config.Database == "" {
+		// every connection opens its own in-memory database
+		conn.SetMaxOpenConns(1)
+	}
+	db.conn = conn
+	return nil
+}
+
+// dsn - file URI with driver params
+func (db *SQLite) dsn() string {
+	database := db.config.Database
+	if database == "" {
+		database = sqliteMemory
+	}
+	params := url.Values{}
+	params.Set("_foreign_keys", "1")
+	params.Set("_busy_timeout", "5000")
+	if db.config.ReadOnly {
+		params.Set("mode", "ro")
+	}
+	return "file:" + database + "?" + params.Encode()
+}
+
+func (db *SQLite) checkConnection() error {
+	if db.conn == nil {
+		return db.connect()
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package adapters
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// sslRequestCode - Postgres SSLRequest message code
+const sslRequestCode = 80877103
+
+/*
+TLSConfig - TLS options of adapter connection
+— RootCAFile: PEM with CA certificates to verify server (system pool if empty)
+— CertFile, KeyFile: client certificate
+— ServerName: name to verify server certificate against (Host by default)
+— MinVersion: "1.0", "1.1", "1.2" (default) or "1.3"
+*/
+type TLSConfig struct {
+	RootCAFile         string `json:"rootCAFile"`
+	CertFile           string `json:"certFile"`
+	KeyFile            string `json:"keyFile"`
+	ServerName         string `json:"serverName"`
+	MinVersion         string `json:"minVersion"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+}
+
+/*
+TLSState - negotiated TLS state of connection reported by VerifyConnection
+*/
+type TLSState struct {
+	Enabled bool   `json:"enabled"`
+	Version string `json:"version"`
+	Cipher  string `json:"cipher"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+/*
+Build - building crypto/tls config. host is used as ServerName if it is not set
+*/
+func (t TLSConfig) Build(host string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	if t.MinVersion != "" {
+		v, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("tls: unknown min version %s", t.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+	if t.RootCAFile != "" {
+		pem, err := ioutil.ReadFile(t.RootCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("tls: no certificates found in " + t.RootCAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+/*
+postgresTLSDialer - dialer for lib/pq that negotiates SSL (SSLRequest) and performs
+TLS handshake with provided config. DSN has to have sslmode=disable, TLS is done here
+*/
+type postgresTLSDialer struct {
+	config *tls.Config
+}
+
+func (d postgresTLSDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+func (d postgresTLSDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], sslRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	answer := make([]byte, 1)
+	if _, err := conn.Read(answer); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if answer[0] != 'S' {
+		conn.Close()
+		return nil, errors.New("tls: SSL is not supported by server")
+	}
+	tlsConn := tls.Client(conn, d.config)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
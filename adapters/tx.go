@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// ErrTxStarted - Begin of adapter that is already in transaction
+var ErrTxStarted = errors.New("transaction is already started")
+
+/*
+Tx - adapter running all queries in single transaction of underlying adapter.
+Builder is of underlying adapter, nested Begin returns ErrTxStarted
+*/
+type Tx struct {
+	Adapter
+	tx          *sql.Tx
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+/*
+NewTx - starting transaction of adapter
+*/
+func NewTx(a Adapter) (*Tx, error) {
+	tx, err := a.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Adapter: a, tx: tx}, nil
+}
+
+// Connect - connection is already taken by transaction
+func (t *Tx) Connect() error {
+	return nil
+}
+
+/*
+Exec - executing SQL-query in transaction
+*/
+func (t *Tx) Exec(SQL string, args ...interface{}) (sql.Result, error) {
+	return t.tx.Exec(SQL, args...)
+}
+
+/*
+QueryRow - executing single row query in transaction
+*/
+func (t *Tx) QueryRow(SQL string, args ...interface{}) (*sql.Row, error) {
+	return t.tx.QueryRow(SQL, args...), nil
+}
+
+/*
+Query - executing SQL-query in transaction
+*/
+func (t *Tx) Query(SQL string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.Query(SQL, args...)
+}
+
+/*
+ExecBatch - executing statements one by one in transaction
+*/
+func (t *Tx) ExecBatch(statements []Statement) error {
+	for _, st := range statements {
+		if _, err := t.tx.Exec(st.SQL, st.Args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Begin - transactions are not nested
+func (t *Tx) Begin() (*sql.Tx, error) {
+	return nil, ErrTxStarted
+}
+
+/*
+ExecContext - executing SQL-query with context in transaction
+*/
+func (t *Tx) ExecContext(ctx context.Context, SQL string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, SQL, args...)
+}
+
+/*
+QueryContext - executing SQL-query with context in transaction
+*/
+func (t *Tx) QueryContext(ctx context.Context, SQL string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, SQL, args...)
+}
+
+/*
+AfterCommit - fn is run after successful Commit (e.g. dropping cached rows changed by transaction),
+it is discarded on Rollback
+*/
+func (t *Tx) AfterCommit(fn func()) {
+	t.mu.Lock()
+	t.afterCommit = append(t.afterCommit, fn)
+	t.mu.Unlock()
+}
+
+// Commit - committing transaction
+func (t *Tx) Commit() error {
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+	for _, fn := range t.takeAfterCommit() {
+		fn()
+	}
+	return nil
+}
+
+// Rollback - rolling transaction back
+func (t *Tx) Rollback() error {
+	t.takeAfterCommit()
+	return t.tx.Rollback()
+}
+
+func (t *Tx) takeAfterCommit() []func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fns := t.afterCommit
+	t.afterCommit = nil
+	return fns
+}
+
+// SQLTx - underlying sql transaction
+func (t *Tx) SQLTx() *sql.Tx {
+	return t.tx
+}
@@ -0,0 +1,118 @@
+package builders
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Count - COUNT(column), use "*" to count rows
+func Count(column string) string {
+	return "COUNT(" + column + ")"
+}
+
+// CountDistinct - COUNT(DISTINCT column)
+func CountDistinct(column string) string {
+	return "COUNT(DISTINCT " + column + ")"
+}
+
+// Sum - SUM(column)
+func Sum(column string) string {
+	return "SUM(" + column + ")"
+}
+
+// Avg - AVG(column)
+func Avg(column string) string {
+	return "AVG(" + column + ")"
+}
+
+// Min - MIN(column)
+func Min(column string) string {
+	return "MIN(" + column + ")"
+}
+
+// Max - MAX(column)
+func Max(column string) string {
+	return "MAX(" + column + ")"
+}
+
+// As - aliasing expression: As(Sum("amount"), "total") -> SUM(amount) AS total
+func As(expression, alias string) string {
+	return expression + " AS " + alias
+}
+
+/*
+PercentileCont - continuous percentile expression for Select:
+percentile_cont(0.95) WITHIN GROUP (ORDER BY column)
+*/
+func PercentileCont(fraction float64, column string) string {
+	return "percentile_cont(" + strconv.FormatFloat(fraction, 'f', -1, 64) + ") WITHIN GROUP (ORDER BY " + column + ")"
+}
+
+/*
+PercentileDisc - discrete percentile expression for Select (returns existing value):
+percentile_disc(0.5) WITHIN GROUP (ORDER BY column)
+*/
+func PercentileDisc(fraction float64, column string) string {
+	return "percentile_disc(" + strconv.FormatFloat(fraction, 'f', -1, 64) + ") WITHIN GROUP (ORDER BY " + column + ")"
+}
+
+/*
+ApproxCountDistinct - approximate count of distinct values based on HyperLogLog.
+Requires postgresql-hll extension (CREATE EXTENSION hll)
+*/
+func ApproxCountDistinct(column string) string {
+	return "hll_cardinality(hll_add_agg(hll_hash_any(" + column + ")))"
+}
+
+// isExpression - field is an expression (function call or number constant, e.g. SELECT 1)
+// and shouldn't be prefixed with table alias
+func isExpression(field string) bool {
+	constant := field != ""
+	for _, c := range field {
+		if c == '(' {
+			return true
+		}
+		if c < '0' || c > '9' {
+			constant = false
+		}
+	}
+	return constant
+}
+
+// RowNumber - ROW_NUMBER() window function, use with Window
+func RowNumber() string {
+	return "ROW_NUMBER()"
+}
+
+// Rank - RANK() window function, use with Window
+func Rank() string {
+	return "RANK()"
+}
+
+// DenseRank - DENSE_RANK() window function, use with Window
+func DenseRank() string {
+	return "DENSE_RANK()"
+}
+
+/*
+Window - window function expression for Select (not prefixed with table alias):
+
+	As(Window(RowNumber(), []string{"user_id"}, OrderParam{OrderBy: "created_at", Desc: true}), "n")
+
+renders ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC) AS n.
+Aggregates (Sum, Count...) could be used as fn too
+*/
+func Window(fn string, partitionBy []string, orderBy ...OrderParam) string {
+	var over []string
+	if len(partitionBy) > 0 {
+		over = append(over, "PARTITION BY "+strings.Join(partitionBy, ", "))
+	}
+	if len(orderBy) > 0 {
+		var items []string
+		for _, o := range orderBy {
+			items = append(items, o.OrderBy+o.direction())
+		}
+		over = append(over, "ORDER BY "+strings.Join(items, ", "))
+	}
+	return fn + " OVER (" + strings.Join(over, " ") + ")"
+}
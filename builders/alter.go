@@ -0,0 +1,107 @@
+package builders
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	alterAddColumn  = "ADD COLUMN"
+	alterDropColumn = "DROP COLUMN"
+	alterColumnType = "ALTER COLUMN"
+)
+
+// alteration - single change of ALTER TABLE
+type alteration struct {
+	action     string
+	column     string
+	definition string // type with constraints for ADD COLUMN, type for ALTER COLUMN
+}
+
+/*
+Alter - ALTER TABLE statement, changes are added with AddColumn, DropColumn and AlterColumnType:
+
+	b.Alter("users").AddColumn("age", "integer NOT NULL DEFAULT 0").DropColumn("nickname")
+
+Postgres changes are idempotent (ADD COLUMN IF NOT EXISTS, DROP COLUMN IF EXISTS).
+SQLite and SQL Server take one change per statement, SQLite can't change column type
+*/
+func (sql *postgres) Alter(table string) Builder {
+	sql = sql.clone()
+	sql.queryType = queryTypeAlter
+	sql.parts.table = table
+	return sql
+}
+
+// AddColumn - adding column with definition (type, constraints and default) to altered table
+func (sql *postgres) AddColumn(column, definition string) Builder {
+	return sql.alter(alteration{action: alterAddColumn, column: column, definition: definition})
+}
+
+// DropColumn - dropping column of altered table
+func (sql *postgres) DropColumn(column string) Builder {
+	return sql.alter(alteration{action: alterDropColumn, column: column})
+}
+
+/*
+AlterColumnType - changing type of column of altered table. MySQL definition replaces
+whole column definition (MODIFY COLUMN), so constraints and default have to be repeated
+*/
+func (sql *postgres) AlterColumnType(column, columnType string) Builder {
+	return sql.alter(alteration{action: alterColumnType, column: column, definition: columnType})
+}
+
+func (sql *postgres) alter(a alteration) Builder {
+	sql = sql.clone()
+	if sql.queryType != queryTypeAlter && sql.err == nil {
+		sql.err = errors.New("builder: " + a.action + " " + a.column + " requires Alter(table)")
+	}
+	sql.parts.alter = append(sql.parts.alter, a)
+	return sql
+}
+
+// validateAlter - checking that dialect supports changes of statement
+func (sql *postgres) validateAlter() error {
+	if len(sql.parts.alter) == 0 {
+		return errors.New("builder: no changes for ALTER TABLE " + sql.parts.table)
+	}
+	if len(sql.parts.alter) > 1 && (sql.dialect == dialectSQLite || sql.dialect == dialectMSSQL) {
+		return errors.New("builder: SQLite and SQL Server take single change per ALTER TABLE " + sql.parts.table)
+	}
+	for _, a := range sql.parts.alter {
+		if a.column == "" {
+			return errors.New("builder: " + a.action + " of " + sql.parts.table + " has no column")
+		}
+		if a.action != alterDropColumn && a.definition == "" {
+			return errors.New("builder: " + a.action + " " + a.column + " has no type")
+		}
+		if a.action == alterColumnType && sql.dialect == dialectSQLite {
+			return errors.New("builder: SQLite can't change type of column " + a.column)
+		}
+	}
+	return nil
+}
+
+func (sql *postgres) buildAlter() string {
+	changes := make([]string, len(sql.parts.alter))
+	for i, a := range sql.parts.alter {
+		column := sql.ident(a.column)
+		switch {
+		case sql.isPostgres() && a.action == alterAddColumn:
+			changes[i] = "ADD COLUMN IF NOT EXISTS " + column + " " + a.definition
+		case sql.isPostgres() && a.action == alterDropColumn:
+			changes[i] = "DROP COLUMN IF EXISTS " + column
+		case sql.isPostgres():
+			changes[i] = "ALTER COLUMN " + column + " TYPE " + a.definition
+		case sql.dialect == dialectMSSQL && a.action == alterAddColumn:
+			changes[i] = "ADD " + column + " " + a.definition
+		case a.action == alterColumnType && sql.dialect == dialectMSSQL:
+			changes[i] = "ALTER COLUMN " + column + " " + a.definition
+		case a.action == alterColumnType:
+			changes[i] = "MODIFY COLUMN " + column + " " + a.definition
+		default:
+			changes[i] = strings.TrimSpace(a.action + " " + column + " " + a.definition)
+		}
+	}
+	return queryTypeAlter + sql.buildTable(false) + " " + strings.Join(changes, ", ")
+}
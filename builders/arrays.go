@@ -0,0 +1,45 @@
+package builders
+
+import (
+	"reflect"
+	"strings"
+)
+
+// anyOf - array rendered as ANY(ARRAY[...])
+type anyOf struct {
+	values interface{}
+}
+
+// Any - column matches any of values: column = ANY(ARRAY[...])
+func Any(values interface{}) Operator {
+	return Operator{Sign: "=", Value: anyOf{values}}
+}
+
+// Overlaps - array column has common elements with values: column && ARRAY[...]
+func Overlaps(values interface{}) Operator {
+	return Operator{Sign: "&&", Value: values}
+}
+
+// ArrayContains - array column contains all values: column @> ARRAY[...] (use Contains for JSONB)
+func ArrayContains(values interface{}) Operator {
+	return Operator{Sign: "@>", Value: values}
+}
+
+// isArray - Go slice rendered as Postgres array ([]byte is not)
+func isArray(value interface{}) bool {
+	t := reflect.TypeOf(value)
+	return t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
+
+// array - ARRAY[...] literal, empty slice is rendered as '{}'
+func (sql *postgres) array(value interface{}) string {
+	rv := reflect.ValueOf(value)
+	if rv.Len() == 0 {
+		return "'{}'"
+	}
+	items := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		items = append(items, sql.value(rv.Index(i).Interface()))
+	}
+	return "ARRAY[" + strings.Join(items, ",") + "]"
+}
@@ -1,43 +1,168 @@
 package builders
 
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
 // NewPostgres - Postgres SQL builder
 func NewPostgres() Builder {
 	return &postgres{}
 }
 
+// NewMySQL - SQL builder quoting identifiers with backticks
+func NewMySQL() Builder {
+	return &postgres{identQuote: "`", placeholder: "?", dialect: dialectMySQL}
+}
+
+// NewSQLite - SQL builder for SQLite (? placeholders, INSERT OR REPLACE, rowid limited UPDATE/DELETE)
+func NewSQLite() Builder {
+	return &postgres{placeholder: "?", dialect: dialectSQLite}
+}
+
+// NewMSSQL - SQL builder for SQL Server ([identifiers], @p1 placeholders, TOP/OFFSET FETCH, OUTPUT INSERTED)
+func NewMSSQL() Builder {
+	return &postgres{identQuote: "[", placeholder: "@p", dialect: dialectMSSQL}
+}
+
+// Placeholder - placeholder of n-th (from 1) argument in builder dialect ($1 or ?)
+func Placeholder(b Builder, n int) string {
+	if p, ok := b.(*postgres); ok {
+		return p.bindVar(n)
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// Ident - identifier ("schema.table") quoted where needed in builder dialect, for statements built by hand
+func Ident(b Builder, name string) string {
+	if p, ok := b.(*postgres); ok {
+		return p.ident(name)
+	}
+	return (&postgres{}).ident(name)
+}
+
 /*
-Builder - interface for query builder for adapter and data service
+Builder - interface for query builder for adapter and data service.
+Builders are immutable: every method returns changed copy, so result has to be used
 */
 type Builder interface {
 	Select([]string) Builder
+	Distinct() Builder
+	Count(string) Builder
 	Insert(string) Builder
 	Update(string) Builder
 	Delete() Builder
+	Truncate(string, ...string) Builder
+	Alter(string) Builder
+	AddColumn(string, string) Builder
+	DropColumn(string) Builder
+	AlterColumnType(string, string) Builder
+	CreateIndex(string, Index) Builder
+	DropIndex(string, Index) Builder
 	ReturnID(string) Builder
 	Values(interface{}) Builder
 	Set(interface{}) Builder
+	OnConflict([]string, string) Builder
+	KeepOnConflict([]string) Builder
 	From(string) Builder
+	Alias(string) Builder
 	Where(map[string]interface{}) Builder
+	WhereCondition(Condition) Builder
 	Limit(int, int) Builder
+	GroupBy([]string) Builder
+	Having(map[string]interface{}) Builder
+	Union(Builder) Builder
+	UnionAll(Builder) Builder
 	Join(Join) Builder
 	Order(OrderParam) Builder
-	Build() string
+	SeekAfter([]string, []interface{}) Builder
+	Lock(string, ...string) Builder
+	Explain(bool) Builder
+	Mask([]string) Builder
+	Clone() Builder
+	Build() (string, []interface{}, error)
 }
 
 /*
-Join - joining Repository (table to query)
+Join - joining Repository (table to query).
+ON is Source.Key = main.TargetKey joined by AND with On conditions:
+keys are columns of Source (or qualified "table.column"), values are constants, operators
+or Column references to main table:
+
+	Join{Source: "orders", Key: "user_id", TargetKey: "id", Type: JoinLeft,
+		On: map[string]interface{}{"status": "paid", "created_at>=": Column("signup_at")}}
+
+CROSS join has neither keys nor On. Alias is generated if empty
+(source name, source_N if source is joined more than once), use it to reference joined columns
 */
 type Join struct {
 	Source    string
+	Alias     string
 	Key       string
 	TargetKey string
 	Fields    []string
 	Type      string
+	On        map[string]interface{}
 }
 
-// OrderParam - ordering params
+// joinedSeparator - separator of join alias and column in names of joined columns
+const joinedSeparator = "__"
+
+/*
+JoinedColumn - name of joined column in result: fields of Join are selected as
+alias.field AS alias__field, so they don't collide with columns of main table
+*/
+func JoinedColumn(alias, column string) string {
+	return alias + joinedSeparator + column
+}
+
+/*
+Validate - checking join type and ON conditions
+*/
+func (j Join) Validate() error {
+	if j.Source == "" {
+		return errors.New("join: source is empty")
+	}
+	switch strings.ToUpper(j.Type) {
+	case "", JoinInner, JoinLeft, JoinRight, JoinFull:
+		if (j.Key == "") != (j.TargetKey == "") {
+			return errors.New("join " + j.Source + ": both Key and TargetKey have to be set")
+		}
+		if j.Key == "" && len(j.On) == 0 {
+			return errors.New("join " + j.Source + ": ON condition is empty")
+		}
+	case JoinCross:
+		if j.Key != "" || j.TargetKey != "" || len(j.On) > 0 {
+			return errors.New("join " + j.Source + ": CROSS join can't have ON condition")
+		}
+	default:
+		return errors.New("join " + j.Source + ": unknown type " + j.Type)
+	}
+	return nil
+}
+
+/*
+OrderParam - ordering params. OrderBy is column or expression (lower(name)),
+Nulls places NULL values (NullsFirst/NullsLast), emulated with CASE on MySQL and SQL Server
+*/
 type OrderParam struct {
 	OrderBy string
 	Asc     bool
 	Desc    bool
+	Nulls   string
+}
+
+// direction - ASC/DESC and NULLS placement
+func (o OrderParam) direction() (d string) {
+	if o.Asc {
+		d += " ASC"
+	}
+	if o.Desc {
+		d += " DESC"
+	}
+	if o.Nulls != "" {
+		d += " NULLS " + strings.ToUpper(o.Nulls)
+	}
+	return
 }
@@ -0,0 +1,93 @@
+package builders
+
+import (
+	"reflect"
+	"strings"
+)
+
+/*
+Condition - group of WHERE conditions joined with AND/OR or negated with NOT.
+Items could be maps (key=value pairs joined with AND) or other Conditions:
+
+	builders.And(builders.Or(map[string]interface{}{"a": 1}, map[string]interface{}{"b": 2}), map[string]interface{}{"c": 3})
+
+renders (a = 1 OR b = 2) AND c = 3.
+Condition could be passed to Builder.WhereCondition or as a value of Where map (key is ignored)
+*/
+type Condition struct {
+	operator string
+	items    []interface{}
+}
+
+// And - all items have to match
+func And(items ...interface{}) Condition {
+	return Condition{operator: "AND", items: items}
+}
+
+// Or - any of items has to match
+func Or(items ...interface{}) Condition {
+	return Condition{operator: "OR", items: items}
+}
+
+// Not - negating item (map pairs are joined with AND before negation)
+func Not(item interface{}) Condition {
+	return Condition{operator: "NOT", items: []interface{}{item}}
+}
+
+/*
+Predicate - value of Where map rendered as condition on its key column
+(e.g. repositories.Op with several operators)
+*/
+type Predicate interface {
+	Predicate(column string) Condition
+}
+
+// toMap - converting named map types (e.g. repositories.QueryMap) to map[string]interface{}
+func toMap(item interface{}) (map[string]interface{}, bool) {
+	if m, ok := item.(map[string]interface{}); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(item)
+	mapType := reflect.TypeOf(map[string]interface{}{})
+	if rv.Kind() == reflect.Map && rv.Type().ConvertibleTo(mapType) {
+		return rv.Convert(mapType).Interface().(map[string]interface{}), true
+	}
+	return nil, false
+}
+
+func (sql *postgres) buildCondition(c Condition) string {
+	var parts []string
+	for _, item := range c.items {
+		if sub, ok := item.(Condition); ok {
+			if str := sql.buildCondition(sub); str != "" {
+				parts = append(parts, str)
+			}
+			continue
+		}
+		if m, ok := toMap(item); ok {
+			pairs := sql.buildConditions(m)
+			if len(pairs) == 1 {
+				parts = append(parts, pairs[0])
+			} else if len(pairs) > 1 {
+				parts = append(parts, "("+strings.Join(pairs, " AND ")+")")
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if c.operator == "NOT" {
+		if len(parts) == 1 && strings.HasPrefix(parts[0], "(") {
+			return "NOT " + parts[0]
+		}
+		return "NOT (" + strings.Join(parts, " AND ") + ")"
+	}
+	return wrap(strings.Join(parts, " "+c.operator+" "), len(parts))
+}
+
+func wrap(str string, n int) string {
+	if n > 1 {
+		return "(" + str + ")"
+	}
+	return str
+}
@@ -0,0 +1,37 @@
+package builders
+
+import "time"
+
+// dateLayout - ISO 8601 date
+const dateLayout = "2006-01-02"
+
+/*
+Date - date-only value: Where(map[string]interface{}{"birthday": Date(t)}) compares with '2006-01-02'
+(cast to date in Postgres), time and timezone of t are ignored
+*/
+type Date time.Time
+
+// String - date as YYYY-MM-DD
+func (d Date) String() string {
+	return time.Time(d).Format(dateLayout)
+}
+
+// isPostgres - dialect is Postgres (casts with ::type)
+func (sql *postgres) isPostgres() bool {
+	return sql.dialect == ""
+}
+
+// timeValue - time values as placeholders. Timestamps are passed in UTC,
+// so columns without time zone store same moment regardless of server/app zone
+func (sql *postgres) timeValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return sql.arg(v.UTC()), true
+	case Date:
+		if sql.isPostgres() {
+			return sql.arg(v.String()) + "::date", true
+		}
+		return sql.arg(v.String()), true
+	}
+	return "", false
+}
@@ -0,0 +1,154 @@
+package builders
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// injectionPayloads - seed corpus of FuzzValues
+var injectionPayloads = []string{
+	"",
+	"x",
+	"'",
+	"''",
+	"' OR '1'='1",
+	"'; DROP TABLE users; --",
+	"x' --",
+	"\\'; SELECT 1; --",
+	"$$; SELECT 1; $$",
+	"/* ' */",
+	"$1",
+	"юникод ' ☃",
+}
+
+/*
+FuzzValues - values passed to Where/Values/Set can't change structure of query:
+SQL built with value stripped of literals has to be the same as SQL built with harmless value
+and value has to be passed as argument
+
+	go test ./builders -fuzz FuzzValues
+*/
+func FuzzValues(f *testing.F) {
+	for _, v := range injectionPayloads {
+		f.Add(v)
+	}
+	f.Fuzz(func(t *testing.T, v string) {
+		if err := checkValue(v); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func checkValue(value string) error {
+	for _, query := range fuzzQueries {
+		expected, err := strippedSQL(query(NewPostgres(), "x"))
+		if err != nil {
+			return err
+		}
+		SQL, args, err := query(NewPostgres(), value).Build()
+		if err != nil {
+			return err
+		}
+		actual, err := stripLiterals(SQL)
+		if err != nil {
+			return err
+		}
+		if actual != expected {
+			return errors.New("value changed query structure: " + SQL)
+		}
+		if !inArgs(value, args) {
+			return errors.New("value is not passed as argument: " + SQL)
+		}
+	}
+	return nil
+}
+
+func strippedSQL(b Builder) (string, error) {
+	SQL, _, err := b.Build()
+	if err != nil {
+		return "", err
+	}
+	return stripLiterals(SQL)
+}
+
+// stripLiterals - SQL without string literals, error if literal is not terminated (value escaped from quotes)
+func stripLiterals(SQL string) (string, error) {
+	var out strings.Builder
+	inLiteral := false
+	for i := 0; i < len(SQL); i++ {
+		c := SQL[i]
+		if !inLiteral {
+			if c == '\'' {
+				inLiteral = true
+				out.WriteString("?")
+				continue
+			}
+			out.WriteByte(c)
+			continue
+		}
+		if c == '\'' {
+			if i+1 < len(SQL) && SQL[i+1] == '\'' {
+				i++
+				continue
+			}
+			inLiteral = false
+		}
+	}
+	if inLiteral {
+		return "", errors.New("unterminated string literal in: " + SQL)
+	}
+	return out.String(), nil
+}
+
+func inArgs(v string, args []interface{}) bool {
+	for _, arg := range args {
+		if arg == v {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzQueries - one value per clause, so map ordering doesn't change output
+var fuzzQueries = []func(b Builder, v string) Builder{
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").Where(map[string]interface{}{"name": v})
+	},
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").Where(map[string]interface{}{"name": []string{v, v}})
+	},
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").Where(map[string]interface{}{"name LIKE": v})
+	},
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").Where(map[string]interface{}{"name NOT IN": v})
+	},
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").Where(map[string]interface{}{"name": ILike(v)})
+	},
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").Where(map[string]interface{}{"age": Between(v, v)})
+	},
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").WhereCondition(Or(
+			map[string]interface{}{"name": v},
+			Not(map[string]interface{}{"email": v}),
+		))
+	},
+	func(b Builder, v string) Builder {
+		return b.Select([]string{"id"}).From("users").SeekAfter([]string{"name", "id"}, []interface{}{v, v})
+	},
+	func(b Builder, v string) Builder {
+		return b.Insert("users").Values(map[string]interface{}{"name": v})
+	},
+	func(b Builder, v string) Builder {
+		return b.Insert("users").Values([]map[string]interface{}{{"name": v}, {"name": v}})
+	},
+	func(b Builder, v string) Builder {
+		return b.Update("users").Set(map[string]interface{}{"name": v}).Where(map[string]interface{}{"id": v})
+	},
+	func(b Builder, v string) Builder {
+		return b.Delete().From("users").Where(map[string]interface{}{"id": v})
+	},
+}
@@ -0,0 +1,147 @@
+package builders
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update - rewriting golden files with current output: go test ./builders -run Golden -update
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenCases - statements with several map pairs, so output depends on key ordering
+var goldenCases = []struct {
+	name  string
+	build func() Builder
+}{
+	{"select_where", func() Builder {
+		return NewPostgres().Select([]string{"id", "name"}).From("users").
+			Where(map[string]interface{}{"status": "active", "age >": 18, "name ILIKE": "%ann%", "deleted_at": nil, "role": []string{"admin", "user"}}).
+			Order(OrderParam{OrderBy: "name", Asc: true}).Limit(10, 20)
+	}},
+	{"select_condition", func() Builder {
+		return NewPostgres().Select([]string{"id"}).From("users").WhereCondition(Or(
+			map[string]interface{}{"status": "new", "age": Between(18, 65)},
+			Not(map[string]interface{}{"email": Like("%@test%"), "role": NotIn([]string{"bot"})}),
+		))
+	}},
+	{"select_join", func() Builder {
+		return NewPostgres().Select([]string{"id"}).From("orders").
+			Join(Join{Source: "users", Key: "id", TargetKey: "user_id", Type: "LEFT", Fields: []string{"name"}, On: map[string]interface{}{"active": true, "tenant_id": Column("tenant_id")}}).
+			Where(map[string]interface{}{"total >": 100, "status": "paid"})
+	}},
+	{"select_group", func() Builder {
+		return NewPostgres().Select([]string{"status", "COUNT(*)"}).From("orders").
+			Where(map[string]interface{}{"total >": 0, "currency": "EUR"}).
+			GroupBy([]string{"status"}).Having(map[string]interface{}{"COUNT(*) >": 5, "SUM(total) <": 1000})
+	}},
+	{"select_jsonb", func() Builder {
+		return NewPostgres().Select([]string{"id"}).From("users").
+			Where(map[string]interface{}{"data": Contains(map[string]interface{}{"status": "active"}), "data->address->>city": "Oslo"})
+	}},
+	{"insert", func() Builder {
+		return NewPostgres().Insert("users").Values(map[string]interface{}{"name": "ann", "email": "a@b.c", "age": 30, "active": true}).ReturnID("id")
+	}},
+	{"insert_rows", func() Builder {
+		return NewPostgres().Insert("users").Values([]map[string]interface{}{{"name": "ann", "age": 30}, {"email": "b@c.d", "name": "bob"}})
+	}},
+	{"upsert", func() Builder {
+		return NewPostgres().Insert("users").Values(map[string]interface{}{"id": 1, "name": "ann", "email": "a@b.c"}).OnConflict([]string{"id"}, ConflictDoUpdate)
+	}},
+	{"upsert_keep", func() Builder {
+		return NewPostgres().Insert("users").Values(map[string]interface{}{"id": 1, "name": "ann", "created_at": "now", "updated_at": "now"}).
+			OnConflict([]string{"id"}, ConflictDoUpdate).KeepOnConflict([]string{"created_at"})
+	}},
+	{"update", func() Builder {
+		return NewPostgres().Update("users").Set(map[string]interface{}{"name": "ann", "age": 31, "active": false}).
+			Where(map[string]interface{}{"id": 1, "version": 3}).ReturnID("*")
+	}},
+	{"delete", func() Builder {
+		return NewPostgres().Delete().From("users").Where(map[string]interface{}{"status": "banned", "id": In([]int{1, 2, 3})})
+	}},
+	{"mysql_select", func() Builder {
+		return NewMySQL().Select([]string{"id"}).From("users").Where(map[string]interface{}{"status": "active", "age >=": 18}).Limit(5, 0)
+	}},
+	{"mysql_create_index", func() Builder {
+		return NewMySQL().CreateIndex("users", Index{Name: "users_email", Expression: "lower(email)", Unique: true})
+	}},
+	{"mysql_drop_index", func() Builder {
+		return NewMySQL().DropIndex("users", Index{Name: "users_email"})
+	}},
+	{"sqlite_update", func() Builder {
+		return NewSQLite().Update("users").Set(map[string]interface{}{"name": "ann", "age": 31}).Where(map[string]interface{}{"status": "new", "id >": 5}).Limit(1, 0)
+	}},
+	{"mssql_select", func() Builder {
+		return NewMSSQL().Select([]string{"id"}).From("users").Where(map[string]interface{}{"status": "active", "age >=": 18}).
+			Order(OrderParam{OrderBy: "id", Asc: true}).Limit(5, 10)
+	}},
+}
+
+// TestGolden - SQL and arguments of statements are the same as in testdata and between builds
+func TestGolden(t *testing.T) {
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := goldenOutput(tc.build())
+			if err != nil {
+				t.Fatal(err)
+			}
+			// maps are iterated in random order, so every build has to be the same
+			for i := 0; i < 20; i++ {
+				again, err := goldenOutput(tc.build())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again != got {
+					t.Fatalf("output differs between builds:\n%s\n%s", got, again)
+				}
+			}
+			path := filepath.Join("testdata", tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("output:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func goldenOutput(b Builder) (string, error) {
+	SQL, args, err := b.Build()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\n%#v\n", SQL, args), nil
+}
+
+// TestBuildErrors - invalid statements are reported by Build instead of producing invalid SQL
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Builder
+	}{
+		{"no query type", NewPostgres().From("users")},
+		{"no table", NewPostgres().Select([]string{"id"})},
+		{"insert without values", NewPostgres().Insert("users")},
+		{"update without set", NewPostgres().Update("users").Where(map[string]interface{}{"id": 1})},
+		{"invalid nulls", NewPostgres().Select([]string{"id"}).From("users").Order(OrderParam{OrderBy: "id", Nulls: "MIDDLE"})},
+		{"seek without columns", NewPostgres().Select([]string{"id"}).From("users").SeekAfter(nil, []interface{}{1})},
+		{"unmarshalable JSON", NewPostgres().Select([]string{"id"}).From("users").Where(map[string]interface{}{"data": Contains(map[string]interface{}{"f": func() {}})})},
+		{"mysql partial index", NewMySQL().CreateIndex("users", Index{Name: "active_email", Columns: []string{"email"}, Where: "active"})},
+		{"mysql explain", NewMySQL().Select([]string{"id"}).From("users").Explain(false)},
+		{"nil list", NewPostgres().Select([]string{"id"}).From("users").Where(map[string]interface{}{"id": In(nil)})},
+	}
+	for _, tt := range tests {
+		if SQL, _, err := tt.b.Build(); err == nil {
+			t.Errorf("%s: no error, SQL %q", tt.name, SQL)
+		}
+	}
+}
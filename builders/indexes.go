@@ -0,0 +1,111 @@
+package builders
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+Index - index of CreateIndex/DropIndex. Expression is used instead of Columns (lower(email)),
+Where makes index partial (raw SQL, DDL has no parameters).
+Concurrently builds or drops index without blocking writes (Postgres, ignored by other dialects)
+*/
+type Index struct {
+	Name         string
+	Columns      []string
+	Expression   string
+	Unique       bool
+	Where        string
+	Concurrently bool
+}
+
+/*
+CreateIndex - CREATE INDEX statement. Postgres and SQLite skip existing index (IF NOT EXISTS),
+MySQL doesn't support partial indexes, SQL Server expression indexes
+*/
+func (sql *postgres) CreateIndex(table string, index Index) Builder {
+	sql = sql.clone()
+	sql.queryType = queryTypeCreateIndex
+	sql.parts.table = table
+	sql.parts.index = &index
+	return sql
+}
+
+/*
+DropIndex - DROP INDEX statement (IF EXISTS except MySQL). Table is needed by MySQL and SQL Server,
+only Name and Concurrently of index are used
+*/
+func (sql *postgres) DropIndex(table string, index Index) Builder {
+	sql = sql.clone()
+	sql.queryType = queryTypeDropIndex
+	sql.parts.table = table
+	sql.parts.index = &index
+	return sql
+}
+
+func (sql *postgres) validateIndex() error {
+	i := sql.parts.index
+	if i.Name == "" {
+		return errors.New("builder: index of " + sql.parts.table + " has no name")
+	}
+	if sql.queryType == queryTypeDropIndex {
+		return nil
+	}
+	if len(i.Columns) == 0 && i.Expression == "" {
+		return errors.New("builder: index " + i.Name + " has neither columns nor expression")
+	}
+	if i.Where != "" && sql.dialect == dialectMySQL {
+		return errors.New("builder: MySQL doesn't support partial index " + i.Name)
+	}
+	if i.Expression != "" && sql.dialect == dialectMSSQL {
+		return errors.New("builder: SQL Server doesn't support expression index " + i.Name + " (index computed column)")
+	}
+	return nil
+}
+
+func (sql *postgres) buildCreateIndex() string {
+	i := sql.parts.index
+	SQL := "CREATE "
+	if i.Unique {
+		SQL += "UNIQUE "
+	}
+	SQL += "INDEX "
+	if sql.isPostgres() && i.Concurrently {
+		SQL += "CONCURRENTLY "
+	}
+	if sql.isPostgres() || sql.dialect == dialectSQLite {
+		SQL += "IF NOT EXISTS "
+	}
+	target := i.Expression
+	if target == "" {
+		columns := make([]string, len(i.Columns))
+		for n, c := range i.Columns {
+			columns[n] = sql.ident(c)
+		}
+		target = strings.Join(columns, ", ")
+	} else if sql.dialect == dialectMySQL {
+		// MySQL functional key parts are parenthesized
+		target = "(" + target + ")"
+	}
+	SQL += sql.ident(i.Name) + " ON" + sql.buildTable(false) + " (" + target + ")"
+	if i.Where != "" {
+		SQL += " WHERE " + i.Where
+	}
+	return SQL
+}
+
+func (sql *postgres) buildDropIndex() string {
+	i := sql.parts.index
+	SQL := "DROP INDEX "
+	if sql.isPostgres() && i.Concurrently {
+		SQL += "CONCURRENTLY "
+	}
+	if sql.dialect != dialectMySQL {
+		SQL += "IF EXISTS "
+	}
+	SQL += sql.ident(i.Name)
+	if sql.dialect == dialectMySQL || sql.dialect == dialectMSSQL {
+		SQL += " ON" + sql.buildTable(false)
+	}
+	return SQL
+}
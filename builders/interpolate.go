@@ -0,0 +1,157 @@
+package builders
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Interpolate - Postgres SQL with $N placeholders replaced by literals of args, for statements
+that can't have parameters (COPY). Placeholders in string literals, quoted identifiers, dollar
+quotes and comments are left as is. Strings are quoted expecting standard_conforming_strings on,
+values of unsupported types are errors
+*/
+func Interpolate(SQL string, args []interface{}) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(SQL); i++ {
+		c := SQL[i]
+		end := -1
+		switch {
+		case c == '\'' || c == '"':
+			end = closing(SQL, i+1, string(c))
+		case strings.HasPrefix(SQL[i:], "--"):
+			if end = strings.IndexByte(SQL[i:], '\n'); end != -1 {
+				end += i
+			}
+		case strings.HasPrefix(SQL[i:], "/*"):
+			if end = strings.Index(SQL[i+2:], "*/"); end != -1 {
+				end += i + 3
+			}
+		case c == '$' && i+1 < len(SQL) && SQL[i+1] >= '0' && SQL[i+1] <= '9':
+			j := i + 1
+			for j < len(SQL) && SQL[j] >= '0' && SQL[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(SQL[i+1 : j])
+			if n < 1 || n > len(args) {
+				return "", fmt.Errorf("interpolate: no argument for $%d", n)
+			}
+			literal, err := sqlLiteral(args[n-1])
+			if err != nil {
+				return "", fmt.Errorf("interpolate $%d: %v", n, err)
+			}
+			out.WriteString(literal)
+			i = j - 1
+			continue
+		case c == '$':
+			// dollar quote: $$...$$ or $tag$...$tag$
+			if tag := strings.IndexByte(SQL[i+1:], '$'); tag != -1 && isDollarTag(SQL[i+1:i+1+tag]) {
+				delimiter := SQL[i : i+tag+2]
+				if end = strings.Index(SQL[i+len(delimiter):], delimiter); end != -1 {
+					end += i + 2*len(delimiter) - 1
+				}
+			} else {
+				out.WriteByte(c)
+				continue
+			}
+		default:
+			out.WriteByte(c)
+			continue
+		}
+		if end == -1 {
+			// unterminated literal or comment runs to the end
+			end = len(SQL) - 1
+		}
+		out.WriteString(SQL[i : end+1])
+		i = end
+	}
+	return out.String(), nil
+}
+
+// closing - index of closing quote (doubled quotes are escaped), -1 if there is none
+func closing(SQL string, from int, q string) int {
+	for i := from; i < len(SQL); i++ {
+		if SQL[i] != q[0] {
+			continue
+		}
+		if i+1 < len(SQL) && SQL[i+1] == q[0] {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func isDollarTag(tag string) bool {
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// sqlLiteral - Postgres literal of argument value
+func sqlLiteral(value interface{}) (string, error) {
+	if v, ok := value.(driver.Valuer); ok {
+		dv, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		value = dv
+	}
+	switch v := indirect(value).(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		if strings.IndexByte(v, 0) != -1 {
+			return "", errors.New("string contains NUL byte")
+		}
+		return quote(v), nil
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + "'::bytea", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s := fmt.Sprint(v)
+		if strings.HasPrefix(s, "-") {
+			// "a=-1" is fine, but "a-$1" must not become comment "a--1"
+			s = "(" + s + ")"
+		}
+		return s, nil
+	case float32:
+		return floatLiteral(float64(v)), nil
+	case float64:
+		return floatLiteral(v), nil
+	case time.Time:
+		return quote(v.Format(time.RFC3339Nano)), nil
+	}
+	return "", fmt.Errorf("unsupported type %T", value)
+}
+
+func floatLiteral(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "'NaN'::float8"
+	case math.IsInf(f, 1):
+		return "'Infinity'::float8"
+	case math.IsInf(f, -1):
+		return "'-Infinity'::float8"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if f < 0 {
+		s = "(" + s + ")"
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package builders
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// jsonb - JSON document rendered as 'value'::jsonb
+type jsonb string
+
+// jsonError - value that couldn't be encoded to JSON, Build returns err
+type jsonError struct {
+	err error
+}
+
+/*
+Contains - JSONB containment: column @> 'value'::jsonb.
+Value is encoded to JSON unless it's already JSON string:
+
+	map[string]interface{}{"data": builders.Contains(map[string]interface{}{"status": "active"})}
+*/
+func Contains(value interface{}) Operator {
+	if s, ok := value.(string); ok && json.Valid([]byte(s)) {
+		return Operator{Sign: "@>", Value: jsonb(s)}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return Operator{Sign: "@>", Value: jsonError{err}}
+	}
+	return Operator{Sign: "@>", Value: jsonb(b)}
+}
+
+// HasKey - JSONB key existence: column ? 'key'
+func HasKey(key string) Operator {
+	return Operator{Sign: "?", Value: key}
+}
+
+// isJSONPath - key addresses JSON path: "data->address->>city"
+func isJSONPath(key string) bool {
+	return strings.Contains(key, "->")
+}
+
+/*
+splitJSONKey - splitting JSON path key with legacy operator suffix ("data->>age>=")
+into path and operator
+*/
+func splitJSONKey(key string) (path, sign string) {
+	i := strings.LastIndex(key, "->")
+	last := strings.TrimPrefix(key[i+2:], ">")
+	start := len(key) - len(last)
+	if j := strings.IndexAny(last, "=<>!"); j != -1 {
+		return strings.TrimSpace(key[:start+j]), strings.TrimSpace(last[j:])
+	}
+	return key, ""
+}
+
+/*
+jsonPath - rendering path with prefixed column and quoted keys:
+data->address->>city -> t.data->'address'->>'city'. Numeric keys are array indexes
+*/
+func (sql *postgres) jsonPath(key string) string {
+	var path string
+	rest := key
+	for {
+		i := strings.LastIndex(rest, "->")
+		if i == -1 {
+			break
+		}
+		arrow := "->"
+		name := rest[i+2:]
+		if strings.HasPrefix(name, ">") {
+			arrow = "->>"
+			name = name[1:]
+		}
+		name = strings.TrimSpace(name)
+		if _, err := strconv.Atoi(name); err == nil {
+			path = arrow + name + path
+		} else {
+			path = arrow + quote(name) + path
+		}
+		rest = rest[:i]
+	}
+	return sql.column(strings.TrimSpace(rest)) + path
+}
@@ -0,0 +1,66 @@
+package builders
+
+import "strconv"
+
+// useTop - SQL Server SELECT is limited by TOP when there is no offset and order,
+// otherwise by OFFSET ... FETCH
+func (sql *postgres) useTop() bool {
+	return sql.dialect == dialectMSSQL && sql.parts.limit != 0 && sql.parts.offset == 0 &&
+		len(sql.parts.order) == 0 && len(sql.parts.unions) == 0
+}
+
+func (sql *postgres) buildTop() string {
+	if !sql.useTop() {
+		return ""
+	}
+	return " TOP " + strconv.Itoa(sql.parts.limit)
+}
+
+// buildOffsetFetch - OFFSET ... FETCH requires ORDER BY, so order is added if not set
+func (sql *postgres) buildOffsetFetch() (limit string) {
+	if sql.useTop() || (sql.parts.limit == 0 && sql.parts.offset == 0) {
+		return
+	}
+	if len(sql.parts.order) == 0 {
+		limit = " ORDER BY (SELECT NULL)"
+	}
+	limit += " OFFSET " + strconv.Itoa(sql.parts.offset) + " ROWS"
+	if sql.parts.limit != 0 {
+		limit += " FETCH NEXT " + strconv.Itoa(sql.parts.limit) + " ROWS ONLY"
+	}
+	return
+}
+
+// buildOutput - OUTPUT INSERTED.* is SQL Server version of RETURNING
+func (sql *postgres) buildOutput() string {
+	if sql.parts.returnID == "" {
+		return ""
+	}
+	return " OUTPUT INSERTED." + sql.ident(sql.parts.returnID)
+}
+
+// buildMSSQLUpdate - UPDATE TOP (n) t SET ... OUTPUT ... FROM table as t WHERE ...
+func (sql *postgres) buildMSSQLUpdate() (SQL string) {
+	SQL = queryTypeUpdate + sql.buildDMLTop() + " " + sql.alias()
+	SQL += sql.buildSetter()
+	SQL += sql.buildOutput()
+	SQL += sql.buildFrom(true)
+	SQL += sql.buildWhere()
+	return
+}
+
+// buildMSSQLDelete - DELETE TOP (n) t FROM table as t WHERE ...
+func (sql *postgres) buildMSSQLDelete() (SQL string) {
+	SQL = queryTypeDelete + sql.buildDMLTop() + " " + sql.alias()
+	SQL += sql.buildFrom(true)
+	SQL += sql.buildWhere()
+	return
+}
+
+// buildDMLTop - limit of UPDATE/DELETE
+func (sql *postgres) buildDMLTop() string {
+	if sql.parts.limit == 0 {
+		return ""
+	}
+	return " TOP (" + strconv.Itoa(sql.parts.limit) + ")"
+}
@@ -0,0 +1,115 @@
+package builders
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+Operator - comparison operator with value for WHERE clause.
+Could be passed as a value of Where map:
+
+	map[string]interface{}{"name": builders.ILike("%foo%")}
+*/
+type Operator struct {
+	Sign  string
+	Value interface{}
+	unary bool
+}
+
+// Null - sentinel for Where maps: {"deleted_at": builders.Null} renders deleted_at IS NULL (same as nil value)
+var Null = IsNull()
+
+// IsNull - column IS NULL
+func IsNull() Operator {
+	return Operator{Sign: "IS NULL", unary: true}
+}
+
+// IsNotNull - column IS NOT NULL
+func IsNotNull() Operator {
+	return Operator{Sign: "IS NOT NULL", unary: true}
+}
+
+// Like - case-sensitive pattern matching: column LIKE 'value'
+func Like(pattern string) Operator {
+	return Operator{Sign: "LIKE", Value: pattern}
+}
+
+// ILike - case-insensitive pattern matching: column ILIKE 'value'
+func ILike(pattern string) Operator {
+	return Operator{Sign: "ILIKE", Value: pattern}
+}
+
+// Between - range predicate: column BETWEEN low AND high (bounds are inclusive)
+func Between(low, high interface{}) Operator {
+	return Operator{Sign: "BETWEEN", Value: []interface{}{low, high}}
+}
+
+// NotEqual - column <> value
+func NotEqual(value interface{}) Operator {
+	return Operator{Sign: "<>", Value: value}
+}
+
+// NotLike - column NOT LIKE 'value'
+func NotLike(pattern string) Operator {
+	return Operator{Sign: "NOT LIKE", Value: pattern}
+}
+
+// NotILike - column NOT ILIKE 'value'
+func NotILike(pattern string) Operator {
+	return Operator{Sign: "NOT ILIKE", Value: pattern}
+}
+
+// In - column IN (values), values is a slice (single value is a list of one). Empty slice matches nothing
+func In(values interface{}) Operator {
+	return Operator{Sign: "IN", Value: list{values}}
+}
+
+// NotIn - column NOT IN (values), values is a slice (single value is a list of one). Empty slice matches all rows
+func NotIn(values interface{}) Operator {
+	return Operator{Sign: "NOT IN", Value: list{values}}
+}
+
+// keyOperators - operators that could be set as key suffix: "name ILIKE", "status NOT IN"
+var keyOperators = []string{"NOT LIKE", "NOT ILIKE", "NOT IN", "LIKE", "ILIKE"}
+
+// splitKey - splitting key with operator suffix ("name ILIKE") into column and operator
+func splitKey(key string) (column, sign string) {
+	key = strings.TrimSpace(key)
+	upper := strings.ToUpper(key)
+	for _, op := range keyOperators {
+		if strings.HasSuffix(upper, " "+op) {
+			return strings.TrimSpace(key[:len(key)-len(op)]), op
+		}
+	}
+	return key, ""
+}
+
+func (sql *postgres) buildOperator(column string, op Operator) string {
+	if op.Sign == "IN" || op.Sign == "NOT IN" {
+		l, ok := op.Value.(list)
+		if !ok {
+			l = list{op.Value}
+		}
+		if indirect(l.values) == nil {
+			sql.fail(errors.New("builder: " + column + " " + op.Sign + " values are nil (use IsNull)"))
+		}
+		op.Value = l
+	}
+	if op.unary {
+		return sql.column(column) + " " + op.Sign
+	}
+	if sql.dialect == dialectSQLite || sql.dialect == dialectMSSQL {
+		// LIKE is case-insensitive (SQLite for ASCII, SQL Server with default collation)
+		op.Sign = strings.Replace(op.Sign, "ILIKE", "LIKE", 1)
+	}
+	if bounds, ok := op.Value.([]interface{}); ok && op.Sign == "BETWEEN" && len(bounds) == 2 {
+		return sql.column(column) + " BETWEEN " + sql.literal(column, bounds[0]) + " AND " + sql.literal(column, bounds[1])
+	}
+	return sql.column(column) + " " + op.Sign + " " + sql.literal(column, op.Value)
+}
+
+// list - slice rendered as (a,b), empty slice as empty subquery, single value as (a)
+type list struct {
+	values interface{}
+}
@@ -0,0 +1,55 @@
+package builders
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListOperators(t *testing.T) {
+	tests := []struct {
+		name  string
+		where map[string]interface{}
+		sql   string
+		args  []interface{}
+	}{
+		{"not in scalar", map[string]interface{}{"status NOT IN": 5}, "t.status NOT IN ($1)", []interface{}{5}},
+		{"not in string", map[string]interface{}{"status NOT IN": "abc"}, "t.status NOT IN ($1)", []interface{}{"abc"}},
+		{"not in slice", map[string]interface{}{"status NOT IN": []string{"a", "b"}}, "t.status NOT IN ($1,$2)", []interface{}{"a", "b"}},
+		{"in scalar", map[string]interface{}{"status": In(5)}, "t.status IN ($1)", []interface{}{5}},
+		{"in string", map[string]interface{}{"status": In("abc")}, "t.status IN ($1)", []interface{}{"abc"}},
+		{"in bytes", map[string]interface{}{"hash": In([]byte("ab"))}, "t.hash IN ($1)", []interface{}{[]byte("ab")}},
+		{"in empty", map[string]interface{}{"status": In([]int{})}, "t.status IN (SELECT NULL WHERE FALSE)", []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SQL, args, err := NewPostgres().Select([]string{"id"}).From("t").Where(tt.where).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(SQL, "WHERE "+tt.sql) {
+				t.Errorf("SQL %q doesn't contain %q", SQL, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestListOperatorsNil(t *testing.T) {
+	for _, where := range []map[string]interface{}{
+		{"status NOT IN": nil},
+		{"status": In(nil)},
+		{"status": NotIn(nil)},
+	} {
+		if SQL, _, err := NewPostgres().Select([]string{"id"}).From("t").Where(where).Build(); err == nil {
+			t.Errorf("%v: no error, SQL %q", where, SQL)
+		}
+	}
+	// errors of nested conditions are returned too
+	sub := NotExists("orders", map[string]string{"user_id": "id"}, map[string]interface{}{"status": In(nil)})
+	if _, _, err := NewPostgres().Select([]string{"id"}).From("users").Where(map[string]interface{}{"orders": sub}).Build(); err == nil {
+		t.Error("subquery: no error")
+	}
+}
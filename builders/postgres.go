@@ -1,45 +1,102 @@
 package builders
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+type union struct {
+	builder Builder
+	all     bool
+}
+
 type parts struct {
 	table      string
+	alias      string
 	fields     []string
+	distinct   bool
 	where      map[string]interface{}
+	condition  *Condition
+	groupBy    []string
+	unions     []union
+	having     map[string]interface{}
 	join       []Join
 	order      []OrderParam
 	limit      int
 	offset     int
 	insertData interface{}
 	returnID   string
+
+	conflictColumns []string
+	conflictAction  string
+	conflictKeep    []string
+
+	masked  []string
+	lock    string
+	count   string
+	explain string
+	seek    *seek
+
+	truncate []string     // TRUNCATE options
+	alter    []alteration // ALTER TABLE changes
+	index    *Index       // CREATE/DROP INDEX
 }
 
 /*
 postgres - abstract builder for SQL-queries. Now adapted for Postgres
 */
 type postgres struct {
-	queryType string
-	parts     parts
-	sources   map[string]string // map that contains tables with aliases
+	queryType   string
+	identQuote  string // identifier quote of dialect, " by default
+	placeholder string // argument placeholder of dialect: "$" ($1, $2...) or "?"
+	dialect     string // dialect specific rendering: "" (Postgres), "mysql", "sqlite" or "mssql"
+	parts       parts
+	err         error
+	args        *[]interface{} // arguments collected while building
 }
 
 /*
 Select - will set query type to SELECT and sets fields array.
 */
 func (sql *postgres) Select(fields []string) Builder {
+	sql = sql.clone()
 	sql.queryType = queryTypeSelect
 	sql.parts.fields = append(sql.parts.fields, fields...)
 	return sql
 }
 
+/*
+Count - counting rows matched by query: fields are replaced with COUNT(column)
+("*" or empty counts all rows), ORDER BY and LIMIT are discarded
+*/
+func (sql *postgres) Count(column string) Builder {
+	sql = sql.clone()
+	if column == "" {
+		column = "*"
+	}
+	sql.queryType = queryTypeSelect
+	sql.parts.count = column
+	return sql
+}
+
+/*
+Distinct - SELECT DISTINCT
+*/
+func (sql *postgres) Distinct() Builder {
+	sql = sql.clone()
+	sql.parts.distinct = true
+	return sql
+}
+
 /*
 Insert - will set query type to INSERT and sets table
 */
 func (sql *postgres) Insert(table string) Builder {
+	sql = sql.clone()
 	sql.queryType = queryTypeInsert
 	sql.parts.table = table
 	return sql
@@ -49,10 +106,10 @@ func (sql *postgres) Insert(table string) Builder {
 Update — will set queryType to UPDATE and sets table
 */
 func (sql *postgres) Update(table string) Builder {
+	sql = sql.clone()
 	// setting table
 	sql.queryType = queryTypeUpdate
 	sql.parts.table = table
-	sql.addToSources(table, tablePrefix)
 	return sql
 }
 
@@ -60,6 +117,7 @@ func (sql *postgres) Update(table string) Builder {
 Delete — will set queryType to DELETE and sets table
 */
 func (sql *postgres) Delete() Builder {
+	sql = sql.clone()
 	sql.queryType = queryTypeDelete
 	return sql
 }
@@ -75,8 +133,11 @@ func (sql *postgres) Set(data interface{}) Builder {
 Values - map that will be users for Insert.
 — key is for column
 — value for column value
+For multi-row INSERT pass []map[string]interface{} or slice of structs (columns by `db` tag),
+rows missing some columns get DEFAULT
 */
 func (sql *postgres) Values(data interface{}) Builder {
+	sql = sql.clone()
 	sql.parts.insertData = data
 	return sql
 }
@@ -85,8 +146,17 @@ func (sql *postgres) Values(data interface{}) Builder {
 From - will set table for query
 */
 func (sql *postgres) From(table string) Builder {
+	sql = sql.clone()
 	sql.parts.table = table
-	sql.addToSources(table, tablePrefix)
+	return sql
+}
+
+/*
+Alias - setting alias of main table (t by default)
+*/
+func (sql *postgres) Alias(alias string) Builder {
+	sql = sql.clone()
+	sql.parts.alias = alias
 	return sql
 }
 
@@ -94,25 +164,105 @@ func (sql *postgres) From(table string) Builder {
 ReturnID - return auto increment `id` after INSERT query
 */
 func (sql *postgres) ReturnID(id string) Builder {
+	sql = sql.clone()
 	sql.parts.returnID = id
 	return sql
 }
 
+/*
+OnConflict - ON CONFLICT clause for INSERT.
+action is ConflictDoNothing or ConflictDoUpdate (inserted values overwrite all non-conflict columns),
+SQLite also supports ConflictReplace (INSERT OR REPLACE, columns are ignored)
+*/
+func (sql *postgres) OnConflict(columns []string, action string) Builder {
+	sql = sql.clone()
+	sql.parts.conflictColumns = columns
+	sql.parts.conflictAction = action
+	return sql
+}
+
+/*
+KeepOnConflict - columns that ConflictDoUpdate doesn't overwrite in existing row
+(e.g. creation time), they are set by INSERT only
+*/
+func (sql *postgres) KeepOnConflict(columns []string) Builder {
+	sql = sql.clone()
+	sql.parts.conflictKeep = columns
+	return sql
+}
+
 /*
 Where - map that contains keys=values for SELECT/UPDATE/DELETE
 */
 func (sql *postgres) Where(where map[string]interface{}) Builder {
+	sql = sql.clone()
 	sql.parts.where = where
 	return sql
 }
 
+/*
+WhereCondition - condition tree (And/Or/Not) for SELECT/UPDATE/DELETE.
+Joined with Where pairs by AND
+*/
+func (sql *postgres) WhereCondition(c Condition) Builder {
+	sql = sql.clone()
+	sql.parts.condition = &c
+	return sql
+}
+
+/*
+GroupBy - GROUP BY columns or expressions
+*/
+func (sql *postgres) GroupBy(fields []string) Builder {
+	sql = sql.clone()
+	sql.parts.groupBy = append(sql.parts.groupBy, fields...)
+	return sql
+}
+
+/*
+Having - conditions on groups. Same format as Where, keys could be aggregate expressions:
+map[string]interface{}{"COUNT(*)>": 5}
+*/
+func (sql *postgres) Having(having map[string]interface{}) Builder {
+	sql = sql.clone()
+	sql.parts.having = having
+	return sql
+}
+
+/*
+Union - combining SELECT with another one (duplicates are removed).
+Order and Limit of this builder are applied to combined result
+*/
+func (sql *postgres) Union(b Builder) Builder {
+	sql = sql.clone()
+	sql.parts.unions = append(sql.parts.unions, union{builder: b})
+	return sql
+}
+
+/*
+UnionAll - combining SELECT with another one keeping duplicates
+*/
+func (sql *postgres) UnionAll(b Builder) Builder {
+	sql = sql.clone()
+	sql.parts.unions = append(sql.parts.unions, union{builder: b, all: true})
+	return sql
+}
+
 /*
 Join - join source with params into query.
 Every table in SQL query have to have Alias. If you'll not provide - it will be generated
+(source name or source_N if source is already joined)
 */
 func (sql *postgres) Join(jp Join) Builder {
+	sql = sql.clone()
+	err := jp.Validate()
+	if err == nil && jp.Alias != "" && inStrings(jp.Alias, sql.joinAliases()) {
+		err = errors.New("join " + jp.Source + ": alias " + jp.Alias + " is already used")
+	}
+	if err != nil && sql.err == nil {
+		sql.err = err
+	}
 	sql.parts.join = append(sql.parts.join, jp)
-	sql.addToSources(jp.Source, jp.Source)
 	return sql
 }
 
@@ -120,6 +270,14 @@ func (sql *postgres) Join(jp Join) Builder {
 Order - will set order by params for query
 */
 func (sql *postgres) Order(o OrderParam) Builder {
+	sql = sql.clone()
+	switch strings.ToUpper(o.Nulls) {
+	case "", NullsFirst, NullsLast:
+	default:
+		if sql.err == nil {
+			sql.err = errors.New("order " + o.OrderBy + ": nulls has to be " + NullsFirst + " or " + NullsLast)
+		}
+	}
 	sql.parts.order = append(sql.parts.order, o)
 	return sql
 }
@@ -130,72 +288,403 @@ Limit - limit and offset.
 - limit by default is defaultLimit
 */
 func (sql *postgres) Limit(limit, offset int) Builder {
+	sql = sql.clone()
 	sql.parts.limit = limit
 	sql.parts.offset = offset
 	return sql
 }
 
 /*
-Build - method that builds from params into SQL string
+Lock - row locking clause for SELECT: Lock(LockForUpdate, LockSkipLocked) renders FOR UPDATE SKIP LOCKED
+*/
+func (sql *postgres) Lock(mode string, options ...string) Builder {
+	sql = sql.clone()
+	sql.parts.lock = strings.Join(append([]string{mode}, options...), " ")
+	return sql
+}
+
+/*
+Explain - wrapping statement in EXPLAIN (FORMAT JSON) (Postgres).
+With analyze statement is executed (EXPLAIN ANALYZE), so be careful with INSERT/UPDATE/DELETE
 */
-func (sql postgres) Build() string {
-	if sql.queryType == queryTypeSelect {
-		return sql.buildSelect()
+func (sql *postgres) Explain(analyze bool) Builder {
+	sql = sql.clone()
+	sql.parts.explain = "EXPLAIN (FORMAT JSON) "
+	if analyze {
+		sql.parts.explain = "EXPLAIN (ANALYZE, FORMAT JSON) "
+	}
+	return sql
+}
+
+/*
+Mask - rendering values of columns as '***'. Used to log SQL without sensitive data
+*/
+func (sql *postgres) Mask(columns []string) Builder {
+	sql = sql.clone()
+	sql.parts.masked = append(sql.parts.masked, columns...)
+	return sql
+}
+
+/*
+Clone - copy of builder. Chained methods return copies anyway, so base query could be
+derived into variants: base := b.Select(fields).From("users"); active := base.Where(...)
+*/
+func (sql *postgres) Clone() Builder {
+	return sql.clone()
+}
+
+// clone - copy of builder with own slices and maps, so changes of copy don't leak
+func (sql *postgres) clone() *postgres {
+	c := *sql
+	p := &c.parts
+	p.fields = append([]string(nil), p.fields...)
+	p.groupBy = append([]string(nil), p.groupBy...)
+	p.unions = append([]union(nil), p.unions...)
+	p.join = append([]Join(nil), p.join...)
+	p.order = append([]OrderParam(nil), p.order...)
+	p.conflictColumns = append([]string(nil), p.conflictColumns...)
+	p.conflictKeep = append([]string(nil), p.conflictKeep...)
+	p.masked = append([]string(nil), p.masked...)
+	p.truncate = append([]string(nil), p.truncate...)
+	p.alter = append([]alteration(nil), p.alter...)
+	p.where = copyMap(p.where)
+	p.having = copyMap(p.having)
+	return &c
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+/*
+Build - method that builds from params into SQL string with placeholders and arguments.
+Returns error if statement is invalid (no table, no values for INSERT, empty SET for UPDATE, invalid join)
+*/
+func (sql postgres) Build() (string, []interface{}, error) {
+	args := []interface{}{}
+	sql.args = &args
+	SQL, err := sql.build()
+	if err != nil {
+		return "", nil, err
+	}
+	return SQL, args, nil
+}
+
+func (sql *postgres) build() (SQL string, err error) {
+	if err = sql.validate(); err != nil {
+		return
+	}
+	switch sql.queryType {
+	case queryTypeSelect:
+		SQL = sql.buildSelect()
+	case queryTypeInsert:
+		SQL = sql.buildInsert()
+	case queryTypeDelete:
+		SQL = sql.buildDelete()
+	case queryTypeUpdate:
+		SQL = sql.buildUpdate()
+	case queryTypeTruncate:
+		SQL = sql.buildTruncate()
+	case queryTypeAlter:
+		SQL = sql.buildAlter()
+	case queryTypeCreateIndex:
+		SQL = sql.buildCreateIndex()
+	case queryTypeDropIndex:
+		SQL = sql.buildDropIndex()
+	}
+	// errors of nested builders (subqueries, unions)
+	return sql.parts.explain + SQL, sql.err
+}
+
+// validate - checking statement before building
+func (sql *postgres) validate() error {
+	if sql.err != nil {
+		return sql.err
+	}
+	switch sql.queryType {
+	case queryTypeSelect, queryTypeInsert, queryTypeUpdate, queryTypeDelete, queryTypeTruncate, queryTypeAlter,
+		queryTypeCreateIndex, queryTypeDropIndex:
+	case "":
+		return errors.New("builder: query type is not set (Select/Insert/Update/Delete/Truncate/Alter)")
+	default:
+		return errors.New("builder: unknown query type " + sql.queryType)
+	}
+	if sql.parts.table == "" {
+		return errors.New("builder: table is not set for " + sql.queryType)
 	}
 	if sql.queryType == queryTypeInsert {
-		return sql.buildInsert()
+		if columns, _ := insertRows(sql.parts.insertData); len(columns) == 0 {
+			return errors.New("builder: no values for INSERT into " + sql.parts.table)
+		}
+	}
+	if sql.queryType == queryTypeTruncate {
+		if err := sql.validateTruncate(); err != nil {
+			return err
+		}
 	}
-	if sql.queryType == queryTypeDelete {
-		return sql.buildDelete()
+	if sql.queryType == queryTypeAlter {
+		if err := sql.validateAlter(); err != nil {
+			return err
+		}
+	}
+	if sql.queryType == queryTypeCreateIndex || sql.queryType == queryTypeDropIndex {
+		if err := sql.validateIndex(); err != nil {
+			return err
+		}
 	}
 	if sql.queryType == queryTypeUpdate {
-		return sql.buildUpdate()
+		if data, _ := toMap(sql.parts.insertData); len(data) == 0 {
+			return errors.New("builder: SET is empty for UPDATE " + sql.parts.table)
+		}
+	}
+	if sql.parts.conflictAction == ConflictReplace && sql.dialect != dialectSQLite {
+		return errors.New("builder: " + ConflictReplace + " on conflict is supported by SQLite only")
+	}
+	if sql.parts.lock != "" && sql.dialect == dialectSQLite {
+		return errors.New("builder: SQLite doesn't support row locks (" + sql.parts.lock + ")")
+	}
+	if sql.parts.explain != "" && !sql.isPostgres() {
+		return errors.New("builder: EXPLAIN (FORMAT JSON) is supported by Postgres only")
+	}
+	if sql.dialect == dialectMSSQL {
+		if sql.parts.conflictAction != "" {
+			return errors.New("builder: SQL Server doesn't support ON CONFLICT (use MERGE)")
+		}
+		if sql.parts.lock != "" {
+			return errors.New("builder: SQL Server locks are table hints, " + sql.parts.lock + " is not supported")
+		}
+	}
+	return nil
+}
+
+// child - builder for nested query sharing arguments and dialect
+func (sql *postgres) child() *postgres {
+	return &postgres{identQuote: sql.identQuote, placeholder: sql.placeholder, dialect: sql.dialect, args: sql.args}
+}
+
+// embed - building nested builder (subquery, union) with shared arguments
+func (sql *postgres) embed(b Builder) string {
+	if p, ok := b.(*postgres); ok {
+		nested := *p
+		nested.args = sql.args
+		SQL, err := nested.build()
+		if err != nil && sql.err == nil {
+			sql.err = err
+		}
+		return SQL
+	}
+	SQL, args, err := b.Build()
+	if err != nil && sql.err == nil {
+		sql.err = err
 	}
-	return ""
+	*sql.args = append(*sql.args, args...)
+	return SQL
+}
+
+// fail - keeping first error of rendering, it is returned by Build
+func (sql *postgres) fail(err error) {
+	if err != nil && sql.err == nil {
+		sql.err = err
+	}
+}
+
+// arg - adding argument and returning its placeholder
+func (sql *postgres) arg(value interface{}) string {
+	*sql.args = append(*sql.args, value)
+	return sql.bindVar(len(*sql.args))
+}
+
+// nullsOrdering - dialect supports NULLS FIRST/LAST (MySQL and SQL Server don't)
+func (sql *postgres) nullsOrdering() bool {
+	return sql.dialect != dialectMSSQL && sql.dialect != dialectMySQL
+}
+
+// bindVar - placeholder of n-th (from 1) argument
+func (sql *postgres) bindVar(n int) string {
+	switch sql.placeholder {
+	case "?":
+		return "?"
+	case "@p":
+		return "@p" + strconv.Itoa(n)
+	}
+	return "$" + strconv.Itoa(n)
 }
 
 func (sql *postgres) buildUpdate() (SQL string) {
+	if sql.dialect == dialectMSSQL {
+		return sql.buildMSSQLUpdate()
+	}
 	SQL = queryTypeUpdate
 	SQL += sql.buildTable(true)
 	SQL += sql.buildSetter()
-	SQL += sql.buildWhere()
+	SQL += sql.buildLimitedWhere()
+	if sql.parts.returnID != "" {
+		SQL += " RETURNING " + sql.ident(sql.parts.returnID)
+	}
 	return
 }
 func (sql *postgres) buildInsert() (SQL string) {
 	SQL = queryTypeInsert
-	SQL += " INTO " + sql.parts.table
+	if sql.parts.conflictAction == ConflictReplace {
+		SQL += " OR " + ConflictReplace
+	}
+	SQL += " INTO " + sql.ident(sql.parts.table)
+	if sql.dialect == dialectMSSQL {
+		columns, values := sql.buildInsertRows()
+		return SQL + columns + sql.buildOutput() + " VALUES " + values
+	}
 	SQL += sql.buildValues()
+	SQL += sql.buildOnConflict()
 	if sql.parts.returnID != "" {
-		SQL += " RETURNING " + sql.parts.returnID
+		SQL += " RETURNING " + sql.ident(sql.parts.returnID)
 	}
 	return
 }
+func (sql *postgres) buildOnConflict() (SQL string) {
+	if sql.parts.conflictAction == "" || sql.parts.conflictAction == ConflictReplace {
+		return
+	}
+	SQL = " ON CONFLICT"
+	if len(sql.parts.conflictColumns) > 0 {
+		var columns []string
+		for _, column := range sql.parts.conflictColumns {
+			columns = append(columns, sql.ident(column))
+		}
+		SQL += " (" + strings.Join(columns, ",") + ")"
+	}
+	if sql.parts.conflictAction == ConflictDoUpdate {
+		var set []string
+		columns, _ := insertRows(sql.parts.insertData)
+		for _, key := range columns {
+			if !inStrings(key, sql.parts.conflictColumns) && !inStrings(key, sql.parts.conflictKeep) {
+				set = append(set, sql.ident(key)+" = EXCLUDED."+sql.ident(key))
+			}
+		}
+		if len(set) > 0 {
+			return SQL + " DO UPDATE SET " + strings.Join(set, ", ")
+		}
+	}
+	return SQL + " " + ConflictDoNothing
+}
+
 func (sql *postgres) buildDelete() (SQL string) {
+	if sql.dialect == dialectMSSQL {
+		return sql.buildMSSQLDelete()
+	}
 	SQL = queryTypeDelete
 	SQL += sql.buildFrom(true)
-	SQL += sql.buildWhere()
+	SQL += sql.buildLimitedWhere()
 	return
 }
 
+// buildLimitedWhere - WHERE of UPDATE/DELETE. Postgres has no LIMIT there, so it's ignored,
+// SQLite rows are limited by rowid subquery
+func (sql *postgres) buildLimitedWhere() string {
+	if sql.dialect != dialectSQLite || sql.parts.limit == 0 {
+		return sql.buildWhere()
+	}
+	return " WHERE rowid IN (SELECT rowid" + sql.buildFrom(true) + sql.buildWhere() + sql.buildLimit() + ")"
+}
+
 func (sql *postgres) buildValues() string {
-	var keys []string
-	var values []string
+	columns, values := sql.buildInsertRows()
+	return columns + " VALUES " + values
+}
 
-	if data, ok := sql.parts.insertData.(map[string]interface{}); ok {
-		for key, value := range data {
-			keys = append(keys, ""+key+"")
-			values = append(values, toString(value))
+// buildInsertRows - (columns) and value tuples of INSERT
+func (sql *postgres) buildInsertRows() (string, string) {
+	columns, rows := insertRows(sql.parts.insertData)
+	var tuples []string
+	for _, row := range rows {
+		var values []string
+		for _, column := range columns {
+			if value, ok := row[column]; ok {
+				values = append(values, sql.literal(column, value))
+			} else {
+				values = append(values, "DEFAULT")
+			}
 		}
+		tuples = append(tuples, "("+strings.Join(values, ",")+")")
+	}
+	if len(tuples) == 0 {
+		tuples = append(tuples, "()")
+	}
+	var quoted []string
+	for _, column := range columns {
+		quoted = append(quoted, sql.ident(column))
 	}
-	return "(" + strings.Join(keys, ",") + ") VALUES (" + strings.Join(values, ",") + ")"
+	return "(" + strings.Join(quoted, ",") + ")", strings.Join(tuples, ",")
 }
 
 func (sql *postgres) buildSelect() (SQL string) {
+	if sql.parts.count != "" {
+		return sql.buildCount()
+	}
 	SQL = queryTypeSelect
+	if sql.parts.distinct {
+		SQL += " DISTINCT"
+	}
+	SQL += sql.buildTop()
 	SQL += sql.buildFields()
 	SQL += sql.buildFrom(true)
 	SQL += sql.buildJoin()
 	SQL += sql.buildWhere()
+	SQL += sql.buildGroupBy()
+	if len(sql.parts.unions) > 0 {
+		return sql.buildUnion(SQL)
+	}
+	SQL += sql.buildOrderBy()
+	SQL += sql.buildLimit()
+	if sql.parts.lock != "" {
+		SQL += " " + sql.parts.lock
+	}
+	return
+}
+
+/*
+buildCount - counting rows of query without ORDER BY/LIMIT.
+DISTINCT, GROUP BY and UNION queries are counted as subquery
+*/
+func (sql *postgres) buildCount() string {
+	inner := *sql
+	inner.parts.count = ""
+	inner.parts.order = nil
+	inner.parts.limit, inner.parts.offset = 0, 0
+	inner.parts.lock = ""
+	if inner.parts.distinct || len(inner.parts.groupBy) > 0 || len(inner.parts.unions) > 0 {
+		return "SELECT COUNT(*) FROM (" + inner.buildSelect() + ") AS c"
+	}
+	column := sql.parts.count
+	if column != "*" {
+		column = sql.column(column)
+	}
+	inner.parts.fields = []string{Count(column)}
+	inner.parts.join = nil
+	for _, j := range sql.parts.join {
+		j.Fields = nil
+		inner.parts.join = append(inner.parts.join, j)
+	}
+	return inner.buildSelect()
+}
+
+// buildUnion - combining selects, ORDER BY and LIMIT are applied to combined result
+func (sql *postgres) buildUnion(first string) (SQL string) {
+	SQL = "(" + first + ")"
+	for _, u := range sql.parts.unions {
+		if u.all {
+			SQL += " UNION ALL "
+		} else {
+			SQL += " UNION "
+		}
+		SQL += "(" + sql.embed(u.builder) + ")"
+	}
 	SQL += sql.buildOrderBy()
 	SQL += sql.buildLimit()
 	return
@@ -206,9 +695,9 @@ func (sql *postgres) buildFrom(alias bool) string {
 }
 func (sql *postgres) buildTable(alias bool) (t string) {
 	if alias == false {
-		return " " + sql.parts.table
+		return " " + sql.ident(sql.parts.table)
 	}
-	return " " + sql.parts.table + " as " + sql.getAliasBySource(sql.parts.table)
+	return " " + sql.ident(sql.parts.table) + " as " + sql.alias()
 }
 func (sql *postgres) buildFields() string {
 	var fields []string
@@ -216,11 +705,20 @@ func (sql *postgres) buildFields() string {
 		sql.parts.fields = []string{"*"}
 	}
 	for _, f := range sql.parts.fields {
-		fields = append(fields, sql.getAliasBySource(sql.parts.table)+"."+f)
+		if isExpression(f) {
+			fields = append(fields, f)
+			continue
+		}
+		fields = append(fields, sql.alias()+"."+sql.ident(f))
 	}
-	for _, j := range sql.parts.join {
+	aliases := sql.joinAliases()
+	for i, j := range sql.parts.join {
 		for _, f := range j.Fields {
-			fields = append(fields, j.Source+"."+f)
+			field := sql.ident(aliases[i]) + "." + sql.ident(f)
+			if !strings.Contains(strings.ToLower(f), " as ") {
+				field += " AS " + sql.ident(JoinedColumn(aliases[i], f))
+			}
+			fields = append(fields, field)
 		}
 	}
 	return " " + strings.Join(fields, ", ")
@@ -230,16 +728,43 @@ func (sql *postgres) buildJoin() (join string) {
 	if len(sql.parts.join) == 0 {
 		return
 	}
-	for _, j := range sql.parts.join {
-		join += " " + strings.ToUpper(j.Type) + " JOIN " + j.Source + " AS " + j.Source + " ON "
-		join += j.Source + "." + j.Key + " = " + sql.getAliasBySource(sql.parts.table) + "." + j.TargetKey
+	aliases := sql.joinAliases()
+	for i, j := range sql.parts.join {
+		join += " " + strings.ToUpper(j.Type) + " JOIN " + sql.ident(j.Source) + " AS " + sql.ident(aliases[i])
+		if on := sql.buildJoinOn(j, aliases[i]); len(on) > 0 {
+			join += " ON " + strings.Join(on, " AND ")
+		}
 	}
 	return
 }
 
+// buildJoinOn - ON conditions of join, Column references are resolved to main table
+func (sql *postgres) buildJoinOn(j Join, alias string) (on []string) {
+	main := sql.alias()
+	if j.Key != "" {
+		on = append(on, sql.ident(alias)+"."+sql.ident(j.Key)+" = "+main+"."+sql.ident(j.TargetKey))
+	}
+	if len(j.On) == 0 {
+		return
+	}
+	joined := sql.child()
+	joined.parts.table = j.Source
+	joined.parts.alias = alias
+	conditions := make(map[string]interface{}, len(j.On))
+	for key, value := range j.On {
+		if c, ok := value.(Column); ok && !isQualified(string(c)) {
+			value = Column(main + "." + string(c))
+		}
+		conditions[key] = value
+	}
+	on = append(on, joined.buildConditions(conditions)...)
+	sql.fail(joined.err)
+	return on
+}
+
 func formatValue(value interface{}) (fv string) {
 	if v, ok := value.(string); ok {
-		fv = "= '" + v + "'"
+		fv = "= " + quote(v)
 		return
 	}
 	if v, ok := value.([]int64); ok {
@@ -261,7 +786,7 @@ func formatValue(value interface{}) (fv string) {
 	if v, ok := value.([]string); ok {
 		var vs []string
 		for _, n := range v {
-			vs = append(vs, "'"+n+"'")
+			vs = append(vs, quote(n))
 		}
 		fv = " IN (" + strings.Join(vs, ",") + ")"
 		return
@@ -271,59 +796,160 @@ func formatValue(value interface{}) (fv string) {
 }
 
 func (sql *postgres) buildWhere() (where string) {
-	if len(sql.parts.where) == 0 {
+	w := sql.buildConditions(sql.parts.where)
+	if sql.parts.condition != nil {
+		if c := sql.buildCondition(*sql.parts.condition); c != "" {
+			w = append(w, c)
+		}
+	}
+	if c := sql.buildSeek(); c != "" {
+		w = append(w, c)
+	}
+	if len(w) == 0 {
 		return
 	}
-	where = " WHERE "
-	var w []string
-	for key, value := range sql.parts.where {
-		if sl, ok := value.([]int64); ok {
-			var str []string
-			for _, st := range sl {
-				str = append(str, strconv.FormatInt(st, 10))
-			}
-			w = append(w, sql.getAliasBySource(sql.parts.table)+"."+key+" IN ("+strings.Join(str, ",")+")")
+	return " WHERE " + strings.Join(w, " AND ")
+}
+
+// buildConditions - predicates of map pairs in key order, so same map always gives same SQL.
+// Empty conditions (e.g. And() without items) are skipped
+func (sql *postgres) buildConditions(m map[string]interface{}) (w []string) {
+	for _, key := range sortedKeys(m) {
+		if !isCondition(m[key]) && !isConditionKey(key) {
+			sql.fail(errors.New("builder: invalid column in condition " + strconv.Quote(key)))
 			continue
 		}
-		if sl, ok := value.([]string); ok {
-			var str []string
-			for _, st := range sl {
-				str = append(str, `'`+st+`'`)
-			}
-			w = append(w, sql.getAliasBySource(sql.parts.table)+"."+key+" IN ("+strings.Join(str, ",")+")")
-			continue
+		if p := sql.buildPredicate(key, m[key]); p != "" {
+			w = append(w, p)
 		}
-		str := toString(value)
-		sign := ""
-		if strings.Index(key, "=") == -1 && strings.Index(key, ">") == -1 && strings.Index(key, "<") == -1 {
+	}
+	return
+}
+
+// isCondition - value is condition or subquery, its key isn't used as column
+func isCondition(value interface{}) bool {
+	switch value.(type) {
+	case Condition, Subquery:
+		return true
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (sql *postgres) buildPredicate(key string, value interface{}) string {
+	if p, ok := value.(Predicate); ok {
+		return sql.buildCondition(p.Predicate(key))
+	}
+	if c, ok := value.(Condition); ok {
+		return sql.buildCondition(c)
+	}
+	if op, ok := value.(Operator); ok {
+		return sql.buildOperator(key, op)
+	}
+	if sub, ok := value.(Subquery); ok {
+		return sql.buildSubquery(sub)
+	}
+	if c, ok := value.(Column); ok {
+		name := strings.TrimRight(key, "=<>! ")
+		sign := strings.TrimSpace(key[len(name):])
+		if sign == "" {
 			sign = "="
 		}
-		w = append(w, sql.getAliasBySource(sql.parts.table)+"."+key+sign+str)
+		return sql.column(name) + " " + sign + " " + sql.column(string(c))
+	}
+	if sub, ok := value.(Builder); ok {
+		return sql.column(key) + " IN (" + sql.embed(sub) + ")"
 	}
-	return where + strings.Join(w, " AND ")
+	value = indirect(value)
+	if column, sign := splitKey(key); sign != "" {
+		return sql.buildOperator(column, Operator{Sign: sign, Value: value})
+	}
+	if value == nil {
+		return sql.buildOperator(key, IsNull())
+	}
+	if isJSONPath(key) {
+		path, sign := splitJSONKey(key)
+		if sign == "" {
+			sign = "="
+		}
+		return sql.buildOperator(path, Operator{Sign: sign, Value: value})
+	}
+	if sl, ok := value.([]int64); ok {
+		var str []string
+		for _, st := range sl {
+			str = append(str, sql.literal(key, st))
+		}
+		return sql.column(key) + " IN (" + strings.Join(str, ",") + ")"
+	}
+	if sl, ok := value.([]string); ok {
+		var str []string
+		for _, st := range sl {
+			str = append(str, sql.literal(key, st))
+		}
+		return sql.column(key) + " IN (" + strings.Join(str, ",") + ")"
+	}
+	str := sql.literal(key, value)
+	sign := ""
+	if strings.Index(key, "=") == -1 && strings.Index(key, ">") == -1 && strings.Index(key, "<") == -1 {
+		sign = "="
+	}
+	return sql.column(key) + sign + str
 }
 
-func (sql *postgres) buildSetter() (where string) {
-	if len(sql.parts.where) == 0 {
+func (sql *postgres) buildGroupBy() (group string) {
+	if len(sql.parts.groupBy) == 0 {
 		return
 	}
+	var fields []string
+	for _, f := range sql.parts.groupBy {
+		fields = append(fields, sql.column(f))
+	}
+	group = " GROUP BY " + strings.Join(fields, ", ")
+	if len(sql.parts.having) > 0 {
+		// HAVING keys are set in code and could be aggregates: "COUNT(*) >"
+		var having []string
+		for _, key := range sortedKeys(sql.parts.having) {
+			if p := sql.buildPredicate(key, sql.parts.having[key]); p != "" {
+				having = append(having, p)
+			}
+		}
+		group += " HAVING " + strings.Join(having, " AND ")
+	}
+	return
+}
+
+func (sql *postgres) buildSetter() (where string) {
 	where = " SET "
 	var w []string
-	if data, ok := sql.parts.insertData.(map[string]interface{}); ok {
-		for key, value := range data {
-			str := toString(value)
-			w = append(w, ""+key+" = "+str)
+	if data, ok := toMap(sql.parts.insertData); ok {
+		for _, key := range sortedKeys(data) {
+			str := sql.literal(key, data[key])
+			w = append(w, sql.ident(key)+" = "+str)
 		}
 	}
 	return where + strings.Join(w, ", ")
 }
 
 func (sql *postgres) buildLimit() (limit string) {
+	if sql.dialect == dialectMSSQL {
+		return sql.buildOffsetFetch()
+	}
 	if sql.parts.limit != 0 {
 		limit = " LIMIT "
 		limit += strconv.Itoa(sql.parts.limit)
 		limit += " OFFSET "
 		limit += strconv.Itoa(sql.parts.offset)
+	} else if sql.parts.offset != 0 && sql.dialect == dialectSQLite {
+		// SQLite has no OFFSET without LIMIT
+		limit = " LIMIT -1 OFFSET " + strconv.Itoa(sql.parts.offset)
 	}
 	return
 }
@@ -333,47 +959,153 @@ func (sql *postgres) buildOrderBy() (order string) {
 		var arr []string
 		for _, o := range sql.parts.order {
 			var item string
-			if strings.Contains(o.OrderBy, ".") == false {
-				item = sql.getAliasBySource(sql.parts.table) + "." + o.OrderBy
-			} else {
+			if isExpression(o.OrderBy) {
 				item = o.OrderBy
+			} else if strings.Contains(o.OrderBy, ".") == false && len(sql.parts.unions) == 0 {
+				item = sql.alias() + "." + sql.ident(o.OrderBy)
+			} else {
+				item = sql.ident(o.OrderBy)
 			}
-			if o.Asc {
-				item += " ASC"
-			}
-			if o.Desc {
-				item += " DESC"
+			if o.Nulls != "" && !sql.nullsOrdering() {
+				// emulating NULLS FIRST/LAST by sorting on IS NULL first
+				first, last := "0", "1"
+				if strings.ToUpper(o.Nulls) == NullsLast {
+					first, last = last, first
+				}
+				arr = append(arr, "CASE WHEN "+item+" IS NULL THEN "+first+" ELSE "+last+" END")
+				o.Nulls = ""
 			}
-			arr = append(arr, item)
+			arr = append(arr, item+o.direction())
 		}
 		order = " ORDER BY " + strings.Join(arr, ",")
 	}
 	return
 }
 
-func (sql *postgres) addToSources(table, id string) {
-	if sql.sources == nil {
-		sql.sources = make(map[string]string)
+// column - prefixing column with main table alias (expressions are left as is)
+func (sql *postgres) column(name string) string {
+	if isJSONPath(name) && isColumnName(strings.TrimSpace(name[:strings.Index(name, "->")])) {
+		// keys of path are quoted, even if they look like expression
+		return sql.jsonPath(name)
 	}
-	sql.sources[table] = id
+	if isExpression(name) {
+		return name
+	}
+	if isQualified(name) {
+		return sql.ident(name)
+	}
+	return sql.alias() + "." + sql.ident(name)
 }
 
-func (sql *postgres) getAliasBySource(source string) string {
-	if sql.sources[source] != "" {
-		return sql.sources[source]
+// alias - alias of main table
+func (sql *postgres) alias() string {
+	if sql.parts.alias != "" {
+		return sql.parts.alias
 	}
-	return source
+	return tablePrefix
 }
 
-func toString(value interface{}) (str string) {
-	if v, ok := value.(float64); ok {
-		str = strconv.FormatFloat(v, 'f', 8, 64)
-	} else if v, ok := value.(int64); ok {
-		str = strconv.FormatInt(v, 10)
-	} else if v, ok := value.(int); ok {
-		str = strconv.Itoa(v)
+/*
+joinAliases - aliases of joins: explicit Alias or source name,
+suffixed with number when source is joined more than once (self-joins)
+*/
+func (sql *postgres) joinAliases() []string {
+	used := []string{sql.alias(), sql.parts.table}
+	for _, j := range sql.parts.join {
+		if j.Alias != "" {
+			used = append(used, j.Alias)
+		}
+	}
+	aliases := make([]string, len(sql.parts.join))
+	for i, j := range sql.parts.join {
+		if j.Alias != "" {
+			aliases[i] = j.Alias
+			continue
+		}
+		alias := j.Source
+		for n := 2; inStrings(alias, used); n++ {
+			alias = j.Source + "_" + strconv.Itoa(n)
+		}
+		used = append(used, alias)
+		aliases[i] = alias
+	}
+	return aliases
+}
+
+// isQualified - column is qualified with table: "orders.status", "orders.total>="
+func isQualified(name string) bool {
+	if i := strings.IndexAny(name, "=<>! "); i != -1 {
+		name = name[:i]
+	}
+	return strings.Contains(name, ".")
+}
+
+func inStrings(needle string, haystack []string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// literal - value of column as placeholder, masked columns get '***' argument
+func (sql *postgres) literal(column string, value interface{}) string {
+	if len(sql.parts.masked) > 0 {
+		name := strings.TrimRight(column, "=<>! ")
+		if i := strings.LastIndex(name, "."); i != -1 {
+			name = name[i+1:]
+		}
+		if inStrings(name, sql.parts.masked) {
+			return sql.arg("***")
+		}
+	}
+	return sql.value(value)
+}
+
+// value - rendering value as placeholder (arrays and lists as placeholders of elements)
+func (sql *postgres) value(value interface{}) (str string) {
+	value = indirect(value)
+	if v, ok := value.(jsonb); ok {
+		str = sql.arg(string(v)) + "::jsonb"
+	} else if v, ok := value.(jsonError); ok {
+		sql.fail(errors.New("builder: JSON value: " + v.err.Error()))
+		str = "NULL"
+	} else if t, ok := sql.timeValue(value); ok {
+		str = t
+	} else if v, ok := value.(list); ok {
+		values := indirect(v.values)
+		if !isArray(values) {
+			// scalar (string, []byte) is not expanded into elements
+			values = []interface{}{values}
+		}
+		str = "(SELECT NULL WHERE FALSE)"
+		if items := sql.array(values); strings.HasPrefix(items, "ARRAY[") {
+			str = "(" + items[len("ARRAY["):len(items)-1] + ")"
+		}
+	} else if v, ok := value.(anyOf); ok {
+		str = "ANY(" + sql.value(v.values) + ")"
+	} else if isArray(value) {
+		str = sql.array(value)
 	} else {
-		str = fmt.Sprint("'", value, "'")
+		str = sql.arg(value)
 	}
 	return
 }
+
+// indirect - value of pointer (model pointer fields), nil pointer is NULL
+func indirect(value interface{}) interface{} {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr {
+		return value
+	}
+	if rv.IsNil() {
+		return nil
+	}
+	return rv.Elem().Interface()
+}
+
+// quote - string literal with escaped quotes (standard_conforming_strings is expected to be on)
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
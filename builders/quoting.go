@@ -0,0 +1,166 @@
+package builders
+
+import "strings"
+
+// reservedWords - keywords that can't be used as bare identifiers
+var reservedWords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true, "as": true,
+	"asc": true, "asymmetric": true, "both": true, "case": true, "cast": true, "check": true, "collate": true,
+	"column": true, "constraint": true, "create": true, "current_date": true, "current_role": true,
+	"current_time": true, "current_timestamp": true, "current_user": true, "default": true,
+	"deferrable": true, "desc": true, "distinct": true, "do": true, "else": true, "end": true, "except": true,
+	"false": true, "fetch": true, "for": true, "foreign": true, "from": true, "grant": true, "group": true,
+	"having": true, "in": true, "initially": true, "intersect": true, "into": true, "key": true,
+	"lateral": true, "leading": true, "limit": true, "localtime": true, "localtimestamp": true, "not": true,
+	"null": true, "offset": true, "on": true, "only": true, "or": true, "order": true, "placing": true,
+	"primary": true, "references": true, "returning": true, "select": true, "session_user": true,
+	"some": true, "symmetric": true, "table": true, "then": true, "to": true, "trailing": true, "true": true,
+	"union": true, "unique": true, "user": true, "using": true, "variadic": true, "when": true, "where": true,
+	"window": true, "with": true,
+}
+
+// quoteChar - identifier quote of dialect
+func (sql *postgres) quoteChar() string {
+	if sql.identQuote != "" {
+		return sql.identQuote
+	}
+	return `"`
+}
+
+// closingQuote - closing identifier quote, differs for [brackets]
+func closingQuote(q string) string {
+	if q == "[" {
+		return "]"
+	}
+	return q
+}
+
+/*
+ident - quoting identifier ("schema.table", "t.column") where needed: mixed case,
+special characters or reserved words. "*" is left as is,
+anything after identifier (" AS alias", ">=") is kept.
+Expressions set in code (Select fields, GroupBy, typed OrderParam) are checked by callers with isExpression
+*/
+func (sql *postgres) ident(name string) string {
+	q := sql.quoteChar()
+	var parts []string
+	rest := name
+	for {
+		var part string
+		if strings.HasPrefix(rest, q) {
+			// already quoted
+			end := strings.Index(rest[1:], closingQuote(q))
+			if end == -1 {
+				return name
+			}
+			part, rest = rest[:end+2], rest[end+2:]
+		} else if strings.HasPrefix(rest, "*") {
+			part, rest = "*", rest[1:]
+		} else {
+			i := 0
+			for i < len(rest) && isIdentChar(rest[i]) {
+				i++
+			}
+			if i == 0 {
+				break
+			}
+			part, rest = quoteIdentifier(rest[:i], q), rest[i:]
+		}
+		parts = append(parts, part)
+		if !strings.HasPrefix(rest, ".") {
+			break
+		}
+		rest = rest[1:]
+	}
+	return strings.Join(parts, ".") + rest
+}
+
+// quoteIdentifier - quoting single identifier if it isn't lower case word
+func quoteIdentifier(name, q string) string {
+	needed := reservedWords[name] || name[0] >= '0' && name[0] <= '9'
+	for i := 0; i < len(name) && !needed; i++ {
+		c := name[i]
+		needed = !(c == '_' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9')
+	}
+	if !needed {
+		return name
+	}
+	c := closingQuote(q)
+	return q + strings.Replace(name, c, c+c, -1) + c
+}
+
+/*
+isColumnName - name is column or qualified column ("t.name", "\"Name\"") without anything else,
+so condition key from request can't inject expression
+*/
+func isColumnName(name string) bool {
+	rest := name
+	for {
+		if rest != "" && (rest[0] == '"' || rest[0] == '`' || rest[0] == '[') {
+			end := strings.Index(rest[1:], closingQuote(rest[:1]))
+			if end <= 0 {
+				return false
+			}
+			rest = rest[end+2:]
+		} else {
+			i := 0
+			for i < len(rest) && isIdentChar(rest[i]) {
+				i++
+			}
+			if i == 0 || rest[0] == '$' {
+				return false
+			}
+			rest = rest[i:]
+		}
+		if rest == "" {
+			return true
+		}
+		if rest[0] != '.' {
+			return false
+		}
+		rest = rest[1:]
+	}
+}
+
+// conditionSigns - comparison signs that could end condition key: "age >=", "data->>age>="
+var conditionSigns = map[string]bool{"": true, "=": true, "!=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true}
+
+/*
+isConditionKey - key of condition map is column, JSON path of column or column with operator:
+"t.age >=", "name ILIKE", "data->address->>city". Keys of path are quoted by builder
+*/
+func isConditionKey(key string) bool {
+	if strings.TrimSpace(key) != key {
+		return false
+	}
+	column, operator := splitKey(key)
+	if operator == "" {
+		var sign string
+		if isJSONPath(column) {
+			column, sign = splitJSONKey(column)
+		} else {
+			trimmed := strings.TrimRight(column, "=<>! ")
+			column, sign = trimmed, strings.TrimSpace(column[len(trimmed):])
+		}
+		if !conditionSigns[sign] {
+			return false
+		}
+	}
+	parts := strings.Split(column, "->")
+	for _, part := range parts[1:] {
+		if strings.TrimSpace(strings.TrimPrefix(part, ">")) == "" {
+			return false
+		}
+	}
+	return isColumnName(strings.TrimSpace(parts[0]))
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+/*
+Column - reference to column used as value in Where/Join.On maps: {"updated_at>": Column("created_at")}
+renders t.updated_at > t.created_at. Could be qualified: Column("orders.created_at")
+*/
+type Column string
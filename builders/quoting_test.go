@@ -0,0 +1,38 @@
+package builders
+
+import "testing"
+
+func TestConditionKeys(t *testing.T) {
+	valid := []string{"id", "t.id", "age >=", "age>", "id !=", "name ILIKE", "status NOT IN", `"Name"`, "data->>age>=", "data->address->>city", "data->>(1)", "Имя"}
+	for _, key := range valid {
+		if _, _, err := NewPostgres().Select([]string{"id"}).From("users").Where(map[string]interface{}{key: 1}).Build(); err != nil {
+			t.Errorf("%q: %v", key, err)
+		}
+	}
+	invalid := []string{"id=1/**/OR/**/(1)=", "(SELECT 1)", "lower(name)", "id; DROP TABLE users; --", "id OR 1=1 --", "", "t.", `"unterminated`, "data->>x = 1 OR 1=1 --", "data->", "age => 1", "name NOT  LIKE x", " $", "$1", " id"}
+	for _, key := range invalid {
+		if SQL, _, err := NewPostgres().Select([]string{"id"}).From("users").Where(map[string]interface{}{key: 1}).Build(); err == nil {
+			t.Errorf("%q: no error, SQL %q", key, SQL)
+		}
+		if SQL, _, err := NewPostgres().Select([]string{"id"}).From("users").WhereCondition(Or(map[string]interface{}{key: 1})).Build(); err == nil {
+			t.Errorf("%q in condition: no error, SQL %q", key, SQL)
+		}
+		join := Join{Source: "orders", Key: "user_id", TargetKey: "id", On: map[string]interface{}{key: 1}}
+		if SQL, _, err := NewPostgres().Select([]string{"id"}).From("users").Join(join).Build(); err == nil {
+			t.Errorf("%q in join: no error, SQL %q", key, SQL)
+		}
+	}
+	SQL, _, err := NewPostgres().Select([]string{"id"}).From("users").Where(map[string]interface{}{"data->>(1)": 1}).Build()
+	if want := "SELECT t.id FROM  users as t WHERE t.data->>'(1)' = $1"; err != nil || SQL != want {
+		t.Errorf("SQL %q %v, want %q", SQL, err, want)
+	}
+	// aggregates of HAVING and expressions of fields are set in code
+	SQL, _, err = NewPostgres().Select([]string{"status", Count("*")}).From("orders").GroupBy([]string{"status"}).
+		Having(map[string]interface{}{"COUNT(*) >": 5}).Order(OrderParam{OrderBy: "COUNT(*)"}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT t.status, COUNT(*) FROM  orders as t GROUP BY t.status HAVING COUNT(*) >$1 ORDER BY COUNT(*)"; SQL != want {
+		t.Errorf("SQL %q, want %q", SQL, want)
+	}
+}
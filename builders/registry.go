@@ -0,0 +1,58 @@
+package builders
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = make(map[string]func() Builder)
+)
+
+func init() {
+	Register("postgres", NewPostgres)
+	Register("mysql", NewMySQL)
+	Register("sqlite", NewSQLite)
+	Register("mssql", NewMSSQL)
+}
+
+/*
+Register - registering builder factory of dialect, so adapters could use it by name
+(Config.Dialect). Panics if factory is nil or dialect is registered twice
+*/
+func Register(name string, factory func() Builder) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	if factory == nil {
+		panic("builders: factory of dialect " + name + " is nil")
+	}
+	if _, ok := dialects[name]; ok {
+		panic("builders: dialect " + name + " is registered twice")
+	}
+	dialects[name] = factory
+}
+
+// New - builder of registered dialect
+func New(name string) (Builder, error) {
+	dialectsMu.RLock()
+	factory, ok := dialects[name]
+	dialectsMu.RUnlock()
+	if !ok {
+		return nil, errors.New("builders: unknown dialect " + name)
+	}
+	return factory(), nil
+}
+
+// Dialects - names of registered dialects
+func Dialects() []string {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	var names []string
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
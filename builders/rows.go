@@ -0,0 +1,69 @@
+package builders
+
+import (
+	"reflect"
+	"sort"
+)
+
+/*
+insertRows - normalizing INSERT data (map, slice of maps or slice of structs) into rows.
+columns is a sorted union of all row keys, so column order is stable
+*/
+func insertRows(data interface{}) (columns []string, rows []map[string]interface{}) {
+	if m, ok := toMap(data); ok {
+		rows = append(rows, m)
+	} else if rv := reflect.ValueOf(data); rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			item := rv.Index(i).Interface()
+			if m, ok := toMap(item); ok {
+				rows = append(rows, m)
+			} else if m, ok := structToMap(item); ok {
+				rows = append(rows, m)
+			}
+		}
+	} else if m, ok := structToMap(data); ok {
+		rows = append(rows, m)
+	}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return
+}
+
+// structToMap - converting struct fields into map by `db` tag (field name if tag is empty, "-" to skip)
+func structToMap(item interface{}) (map[string]interface{}, bool) {
+	rv := reflect.ValueOf(item)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+	m := make(map[string]interface{})
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		key := field.Tag.Get("db")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		m[key] = rv.Field(i).Interface()
+	}
+	return m, true
+}
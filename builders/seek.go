@@ -0,0 +1,68 @@
+package builders
+
+import (
+	"errors"
+	"strings"
+)
+
+// seek - position of keyset pagination (SeekAfter)
+type seek struct {
+	columns []string
+	values  []interface{}
+}
+
+/*
+SeekAfter - keyset pagination: rows following lastValues of columns, ordered by columns ascending.
+With Limit(n, 0) renders WHERE (a, b) > ($1, $2) ORDER BY a, b LIMIT n, so deep pages are as fast
+as the first one unlike OFFSET. Columns have to be unique together (add id as last one) and indexed.
+Empty lastValues is the first page
+*/
+func (sql *postgres) SeekAfter(columns []string, lastValues []interface{}) Builder {
+	sql = sql.clone()
+	if sql.err == nil {
+		if len(columns) == 0 {
+			sql.err = errors.New("seek: no columns")
+		} else if len(lastValues) > 0 && len(lastValues) != len(columns) {
+			sql.err = errors.New("seek: number of values doesn't match columns " + strings.Join(columns, ", "))
+		}
+	}
+	sql.parts.seek = &seek{
+		columns: append([]string(nil), columns...),
+		values:  append([]interface{}(nil), lastValues...),
+	}
+	for _, c := range columns {
+		sql.parts.order = append(sql.parts.order, OrderParam{OrderBy: c, Asc: true})
+	}
+	return sql
+}
+
+// buildSeek - condition of rows after seek position. SQL Server has no row value
+// comparison, so it is expanded: a > $1 OR (a = $1 AND b > $2)
+func (sql *postgres) buildSeek() string {
+	s := sql.parts.seek
+	if s == nil || len(s.values) == 0 {
+		return ""
+	}
+	if len(s.columns) == 1 {
+		return sql.column(s.columns[0]) + " > " + sql.literal(s.columns[0], s.values[0])
+	}
+	if sql.dialect == dialectMSSQL {
+		var or []string
+		for i := range s.columns {
+			var and []string
+			for j := 0; j < i; j++ {
+				and = append(and, sql.column(s.columns[j])+" = "+sql.literal(s.columns[j], s.values[j]))
+			}
+			and = append(and, sql.column(s.columns[i])+" > "+sql.literal(s.columns[i], s.values[i]))
+			or = append(or, wrap(strings.Join(and, " AND "), len(and)))
+		}
+		return "(" + strings.Join(or, " OR ") + ")"
+	}
+	columns := make([]string, len(s.columns))
+	values := make([]string, len(s.values))
+	for i, c := range s.columns {
+		columns[i] = sql.column(c)
+		values[i] = sql.literal(c, s.values[i])
+	}
+	return "(" + strings.Join(columns, ", ") + ") > (" + strings.Join(values, ", ") + ")"
+}
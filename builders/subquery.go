@@ -0,0 +1,67 @@
+package builders
+
+import (
+	"sort"
+	"strings"
+)
+
+/*
+Subquery - EXISTS subquery correlated with main table.
+Could be passed as a value of Where map (key is ignored):
+
+	map[string]interface{}{"paid": builders.Exists("orders", map[string]string{"user_id": "id"}, map[string]interface{}{"status": "paid"})}
+
+renders EXISTS (SELECT 1 FROM orders AS t_sub WHERE t_sub.user_id = t.id AND t_sub.status='paid')
+*/
+type Subquery struct {
+	source string
+	on     map[string]string
+	where  map[string]interface{}
+	not    bool
+}
+
+// Exists - rows of source matching on (subquery column -> main column) and where exist
+func Exists(source string, on map[string]string, where map[string]interface{}) Subquery {
+	return Subquery{source: source, on: on, where: where}
+}
+
+// NotExists - no rows of source match on (subquery column -> main column) and where: parents with no children
+func NotExists(source string, on map[string]string, where map[string]interface{}) Subquery {
+	return Subquery{source: source, on: on, where: where, not: true}
+}
+
+/*
+LeftJoinWhereNull - rows without matching row in joined source
+(LEFT JOIN source ... WHERE source.key IS NULL), rendered as NOT EXISTS anti-join
+*/
+func LeftJoinWhereNull(j Join) Subquery {
+	return NotExists(j.Source, map[string]string{j.Key: j.TargetKey}, nil)
+}
+
+func (sql *postgres) buildSubquery(s Subquery) string {
+	sub := sql.child()
+	sub.parts.table = s.source
+	sub.parts.alias = sql.alias() + "_sub"
+
+	var keys []string
+	for key := range s.on {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var w []string
+	for _, key := range keys {
+		w = append(w, sub.column(key)+" = "+sql.column(s.on[key]))
+	}
+	w = append(w, sub.buildConditions(s.where)...)
+	sql.fail(sub.err)
+
+	SQL := "EXISTS (SELECT 1 FROM " + sql.ident(s.source) + " AS " + sub.alias()
+	if len(w) > 0 {
+		SQL += " WHERE " + strings.Join(w, " AND ")
+	}
+	SQL += ")"
+	if s.not {
+		return "NOT " + SQL
+	}
+	return SQL
+}
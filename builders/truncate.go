@@ -0,0 +1,46 @@
+package builders
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+Truncate - emptying table (TRUNCATE) with options TruncateCascade and TruncateRestartIdentity (Postgres).
+MySQL and SQL Server render TRUNCATE TABLE without options, SQLite has no TRUNCATE, so
+it is DELETE without WHERE (optimized by SQLite into truncation)
+*/
+func (sql *postgres) Truncate(table string, options ...string) Builder {
+	sql = sql.clone()
+	sql.queryType = queryTypeTruncate
+	sql.parts.table = table
+	sql.parts.truncate = append([]string(nil), options...)
+	return sql
+}
+
+func (sql *postgres) buildTruncate() string {
+	switch {
+	case sql.dialect == dialectSQLite:
+		return queryTypeDelete + sql.buildFrom(false)
+	case sql.isPostgres():
+		SQL := queryTypeTruncate + sql.buildTable(false)
+		if len(sql.parts.truncate) > 0 {
+			SQL += " " + strings.Join(sql.parts.truncate, " ")
+		}
+		return SQL
+	}
+	return queryTypeTruncate + " TABLE" + sql.buildTable(false)
+}
+
+// validateTruncate - checking TRUNCATE options
+func (sql *postgres) validateTruncate() error {
+	for _, o := range sql.parts.truncate {
+		if o != TruncateCascade && o != TruncateRestartIdentity {
+			return errors.New("builder: unknown TRUNCATE option " + o)
+		}
+		if !sql.isPostgres() {
+			return errors.New("builder: TRUNCATE " + o + " is supported by Postgres only")
+		}
+	}
+	return nil
+}
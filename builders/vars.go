@@ -7,13 +7,62 @@ const (
 	JoinRight = "RIGHT"
 	// JoinInner - constant for SQL query builder
 	JoinInner = "INNER"
+	// JoinFull - constant for SQL query builder
+	JoinFull = "FULL"
+	// JoinCross - constant for SQL query builder
+	JoinCross = "CROSS"
 )
 
 const (
-	queryTypeSelect = "SELECT"
-	queryTypeInsert = "INSERT"
-	queryTypeUpdate = "UPDATE"
-	queryTypeDelete = "DELETE"
-	tablePrefix     = "t"
-	defaultLimit    = 100
+	// ConflictDoNothing - skip conflicting INSERT (ON CONFLICT DO NOTHING)
+	ConflictDoNothing = "DO NOTHING"
+	// ConflictDoUpdate - update conflicting row with inserted values (ON CONFLICT DO UPDATE)
+	ConflictDoUpdate = "DO UPDATE"
+	// ConflictReplace - deleting conflicting row and inserting new one (SQLite INSERT OR REPLACE)
+	ConflictReplace = "REPLACE"
+)
+
+const (
+	// LockForUpdate - locking selected rows for update/delete (SELECT ... FOR UPDATE)
+	LockForUpdate = "FOR UPDATE"
+	// LockForNoKeyUpdate - like LockForUpdate but doesn't block FOR KEY SHARE (foreign key checks)
+	LockForNoKeyUpdate = "FOR NO KEY UPDATE"
+	// LockForShare - shared lock, blocks updates but not other FOR SHARE
+	LockForShare = "FOR SHARE"
+	// LockForKeyShare - weakest lock, blocks only key updates and deletes
+	LockForKeyShare = "FOR KEY SHARE"
+	// LockNoWait - option: failing instead of waiting for locked rows
+	LockNoWait = "NOWAIT"
+	// LockSkipLocked - option: skipping locked rows (job queues)
+	LockSkipLocked = "SKIP LOCKED"
+)
+
+const (
+	// TruncateCascade - truncating tables referencing truncated one by foreign keys
+	TruncateCascade = "CASCADE"
+	// TruncateRestartIdentity - resetting sequences of truncated table columns
+	TruncateRestartIdentity = "RESTART IDENTITY"
+)
+
+const (
+	// NullsFirst - NULL values go first in ORDER BY
+	NullsFirst = "FIRST"
+	// NullsLast - NULL values go last in ORDER BY
+	NullsLast = "LAST"
+)
+
+const (
+	queryTypeSelect      = "SELECT"
+	queryTypeInsert      = "INSERT"
+	queryTypeUpdate      = "UPDATE"
+	queryTypeDelete      = "DELETE"
+	queryTypeTruncate    = "TRUNCATE"
+	queryTypeAlter       = "ALTER TABLE"
+	queryTypeCreateIndex = "CREATE INDEX"
+	queryTypeDropIndex   = "DROP INDEX"
+	tablePrefix          = "t"
+	dialectMySQL         = "mysql"
+	dialectSQLite        = "sqlite"
+	dialectMSSQL         = "mssql"
+	defaultLimit         = 100
 )
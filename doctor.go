@@ -0,0 +1,89 @@
+package vodka
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+Check - result of single startup check of Doctor
+*/
+type Check struct {
+	Component string `json:"component"`
+	Name      string `json:"name"`
+	OK        bool   `json:"ok"`
+	Message   string `json:"message,omitempty"`
+}
+
+/*
+Readiness - summary of Doctor: service is ready if all checks passed
+*/
+type Readiness struct {
+	Ready  bool    `json:"ready"`
+	Checks []Check `json:"checks"`
+}
+
+/*
+Diagnoser - component checked by Doctor (repositories, database extensions)
+*/
+type Diagnoser interface {
+	Diagnose(ctx context.Context) []Check
+}
+
+// DiagnoserFunc - function as Diagnoser
+type DiagnoserFunc func(ctx context.Context) []Check
+
+// Diagnose - calling f
+func (f DiagnoserFunc) Diagnose(ctx context.Context) []Check {
+	return f(ctx)
+}
+
+var (
+	diagnosersMu sync.Mutex
+	diagnosers   []Diagnoser
+)
+
+/*
+RegisterDiagnoser - adding component to checks of Doctor, e.g. repositories:
+
+	vodka.RegisterDiagnoser(users)
+	vodka.RegisterDiagnoser(repositories.Extensions(adapter, "pgcrypto", "pg_trgm"))
+*/
+func RegisterDiagnoser(d Diagnoser) {
+	diagnosersMu.Lock()
+	defer diagnosersMu.Unlock()
+	diagnosers = append(diagnosers, d)
+}
+
+/*
+Doctor - running checks of registered components at service boot: connectivity, schema of
+registered models, indexes of declared relations, extensions. Components are checked
+concurrently, ctx limits time of checks
+*/
+func Doctor(ctx context.Context) Readiness {
+	diagnosersMu.Lock()
+	list := append([]Diagnoser(nil), diagnosers...)
+	diagnosersMu.Unlock()
+
+	results := make([][]Check, len(list))
+	var wg sync.WaitGroup
+	for i, d := range list {
+		wg.Add(1)
+		go func(i int, d Diagnoser) {
+			defer wg.Done()
+			results[i] = d.Diagnose(ctx)
+		}(i, d)
+	}
+	wg.Wait()
+
+	r := Readiness{Ready: true, Checks: []Check{}}
+	for _, checks := range results {
+		for _, c := range checks {
+			if !c.OK {
+				r.Ready = false
+			}
+			r.Checks = append(r.Checks, c)
+		}
+	}
+	return r
+}
@@ -27,6 +27,28 @@ func NewUnathorizedError(message string, info interface{}) error {
 	return NewError(ErrorUnathorizedCode, message, info)
 }
 
+// NewNotFoundError - 404 error decorator
+func NewNotFoundError(message string, info interface{}) error {
+	return NewError(ErrorNotFoundCode, message, info)
+}
+
+// IsNotFound - err is 404 Error (e.g. FindByID/FindOne found nothing)
+func IsNotFound(err error) bool {
+	e, ok := err.(Error)
+	return ok && e.httpCode == ErrorNotFoundCode
+}
+
+// NewConflictError - 409 error decorator
+func NewConflictError(message string, info interface{}) error {
+	return NewError(ErrorConflictCode, message, info)
+}
+
+// IsConflict - err is 409 Error (e.g. Update of item changed by someone else)
+func IsConflict(err error) bool {
+	e, ok := err.(Error)
+	return ok && e.httpCode == ErrorConflictCode
+}
+
 // NewError - Error constructor
 func NewError(httpCode int, message string, info interface{}) error {
 	buf := make([]byte, 2048)
@@ -45,3 +67,8 @@ func NewError(httpCode int, message string, info interface{}) error {
 func (e Error) Error() string {
 	return e.Message
 }
+
+// HTTPCode - HTTP status code of error
+func (e Error) HTTPCode() int {
+	return e.httpCode
+}
@@ -0,0 +1,35 @@
+package vodka
+
+import "github.com/niklucky/vodka/builders"
+
+/*
+Sourcer - repository with source (table) name
+*/
+type Sourcer interface {
+	Source() string
+}
+
+/*
+Exists - EXISTS condition for QueryMap: rows of sub repository matching joinCondition
+(sub repository column -> main column) and optional where. Key of QueryMap is ignored:
+
+	repositories.QueryMap{"paid": vodka.Exists(orders, map[string]string{"user_id": "id"}, repositories.QueryMap{"status": "paid"})}
+*/
+func Exists(sub Sourcer, joinCondition map[string]string, where ...map[string]interface{}) builders.Subquery {
+	var w map[string]interface{}
+	if len(where) > 0 {
+		w = where[0]
+	}
+	return builders.Exists(sub.Source(), joinCondition, w)
+}
+
+/*
+NotExists - NOT EXISTS condition for QueryMap: rows without matching rows of sub repository
+*/
+func NotExists(sub Sourcer, joinCondition map[string]string, where ...map[string]interface{}) builders.Subquery {
+	var w map[string]interface{}
+	if len(where) > 0 {
+		w = where[0]
+	}
+	return builders.NotExists(sub.Source(), joinCondition, w)
+}
@@ -18,6 +18,10 @@ const (
 	ErrorServerErrorCode = 500
 	// ErrorAccessDeniedCode - server HTTP code for ServerError 403
 	ErrorAccessDeniedCode = 403
+	// ErrorNotFoundCode - server HTTP code for NotFound 404
+	ErrorNotFoundCode = 404
+	// ErrorConflictCode - server HTTP code for Conflict 409
+	ErrorConflictCode = 409
 	// StatusOK - response with code 200
 	StatusOK = 200
 	// StatusNoContent - response with code 204
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/niklucky/vodka"
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+// aggregateName - columns and aliases of Aggregate (they are not bound as parameters)
+var aggregateName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+/*
+Aggregation - projection of Aggregate: Fn (SUM, AVG, MIN, MAX or COUNT) of Column
+("*" for COUNT of rows) returned as Alias (fn_column by default, e.g. sum_amount)
+*/
+type Aggregation struct {
+	Fn     string `json:"fn"`
+	Column string `json:"column"`
+	Alias  string `json:"alias"`
+}
+
+/*
+AggregateSpec - params of Aggregate
+— GroupBy: columns rows are grouped by, returned with aggregates
+— Aggregates: projections of every group
+— Having: conditions on aggregates, e.g. {"SUM(amount)>": 100}
+*/
+type AggregateSpec struct {
+	GroupBy    []string
+	Aggregates []Aggregation
+	Having     map[string]interface{}
+}
+
+/*
+Aggregate - aggregates of rows matching query grouped by spec columns (reports, dashboards).
+Rows are ordered by group columns, mapper is not applied
+*/
+func (ds *Postgres) Aggregate(query QueryMap, spec AggregateSpec) ([]map[string]interface{}, error) {
+	if err := ds.checkAllowed(query, nil); err != nil {
+		return nil, err
+	}
+	if len(spec.Aggregates) == 0 {
+		return nil, vodka.NewBadRequestError("invalid_aggregate", "Aggregates are not set")
+	}
+	fields := make([]string, 0, len(spec.GroupBy)+len(spec.Aggregates))
+	for _, column := range spec.GroupBy {
+		if !aggregateName.MatchString(column) {
+			return nil, vodka.NewBadRequestError("invalid_aggregate", "Invalid column "+column)
+		}
+		fields = append(fields, column)
+	}
+	for _, a := range spec.Aggregates {
+		field, err := a.expression()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	builder := ds.adapter.Builder().Select(fields).From(ds.source).Where(query)
+	if len(spec.GroupBy) > 0 {
+		builder = builder.GroupBy(spec.GroupBy)
+		for _, column := range spec.GroupBy {
+			builder = builder.Order(builders.OrderParam{OrderBy: column, Asc: true})
+		}
+	}
+	if len(spec.Having) > 0 {
+		builder = builder.Having(spec.Having)
+	}
+	SQL, args, err := ds.build("Aggregate", builder)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.QueryMapRows(ds.adapter, SQL, args...)
+}
+
+// expression - aggregate field of Select: FN(column) AS alias
+func (a Aggregation) expression() (string, error) {
+	fn := strings.ToUpper(a.Fn)
+	if !inArray(fn, aggFunctions) {
+		return "", vodka.NewBadRequestError("invalid_aggregate", a.Fn)
+	}
+	if !aggregateName.MatchString(a.Column) && !(a.Column == "*" && fn == "COUNT") {
+		return "", vodka.NewBadRequestError("invalid_aggregate", "Invalid column "+a.Column)
+	}
+	alias := a.Alias
+	if alias == "" {
+		alias = strings.ToLower(fn) + "_" + a.Column
+		if a.Column == "*" {
+			alias = "count"
+		}
+	}
+	if !aggregateName.MatchString(alias) {
+		return "", vodka.NewBadRequestError("invalid_aggregate", "Invalid alias "+alias)
+	}
+	return builders.As(fn+"("+a.Column+")", alias), nil
+}
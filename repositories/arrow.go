@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/niklucky/vodka/adapters"
+)
+
+/*
+ExportArrow - fetching rows by query and params (same as Find) into single Arrow record.
+Column types are taken from database: integers, floats (and numerics), booleans, timestamps
+and binary are typed, everything else (text, json, arrays) is exported as string.
+Record has to be released by caller
+*/
+func (ds *Postgres) ExportArrow(query QueryMap, params interface{}) (arrow.Record, error) {
+	SQL, args, err := ds.build("ExportArrow", ds.selectBuilder(query, parseParams(params)))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanArrow(rows)
+}
+
+/*
+ExportParquet - writing rows by query and params (same as Find) into w as Parquet file
+*/
+func (ds *Postgres) ExportParquet(w io.Writer, query QueryMap, params interface{}) error {
+	record, err := ds.ExportArrow(query, params)
+	if err != nil {
+		return err
+	}
+	defer record.Release()
+	fw, err := pqarrow.NewFileWriter(record.Schema(), w, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	if err != nil {
+		return err
+	}
+	if err := fw.Write(record); err != nil {
+		fw.Close()
+		return err
+	}
+	return fw.Close()
+}
+
+func scanArrow(rows *sql.Rows) (arrow.Record, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]arrow.Field, len(types))
+	for i, t := range types {
+		fields[i] = arrow.Field{Name: t.Name(), Type: arrowType(t.DatabaseTypeName()), Nullable: true}
+	}
+	rb := array.NewRecordBuilder(memory.DefaultAllocator, arrow.NewSchema(fields, nil))
+	defer rb.Release()
+
+	dest := make([]interface{}, len(types))
+	raw := make([]interface{}, len(types))
+	for i := range types {
+		dest[i] = &raw[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, t := range types {
+			appendArrow(rb.Field(i), t.DatabaseTypeName(), raw[i])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rb.NewRecord(), nil
+}
+
+// arrowType - Arrow type of column by database type name
+func arrowType(dbType string) arrow.DataType {
+	switch strings.ToUpper(dbType) {
+	case "INT", "INT2", "INT4", "INT8", "SMALLINT", "INTEGER", "BIGINT", "SERIAL", "BIGSERIAL", "TINYINT", "MEDIUMINT":
+		return arrow.PrimitiveTypes.Int64
+	case "FLOAT4", "FLOAT8", "NUMERIC", "DECIMAL", "REAL", "DOUBLE", "FLOAT", "MONEY":
+		return arrow.PrimitiveTypes.Float64
+	case "BOOL", "BOOLEAN":
+		return arrow.FixedWidthTypes.Boolean
+	case "TIMESTAMP", "TIMESTAMPTZ", "DATE", "DATETIME":
+		return arrow.FixedWidthTypes.Timestamp_us
+	case "BYTEA", "BLOB", "BINARY", "VARBINARY":
+		return arrow.BinaryTypes.Binary
+	}
+	return arrow.BinaryTypes.String
+}
+
+func appendArrow(b array.Builder, dbType string, raw interface{}) {
+	if raw == nil {
+		b.AppendNull()
+		return
+	}
+	v := adapters.ConvertValue(dbType, raw)
+	switch b := b.(type) {
+	case *array.Int64Builder:
+		b.Append(getInt64(v))
+	case *array.Float64Builder:
+		b.Append(getFloat64(v))
+	case *array.BooleanBuilder:
+		b.Append(getBool(v))
+	case *array.TimestampBuilder:
+		if t := getTime(v); !t.IsZero() {
+			b.Append(arrow.Timestamp(t.UnixMicro()))
+			return
+		}
+		b.AppendNull()
+	case *array.BinaryBuilder:
+		if bytes, ok := v.([]byte); ok {
+			b.Append(bytes)
+			return
+		}
+		b.Append([]byte(fmt.Sprint(v)))
+	case *array.StringBuilder:
+		// raw value keeps json and arrays as sent by database
+		if bytes, ok := raw.([]byte); ok {
+			b.Append(string(bytes))
+			return
+		}
+		b.Append(fmt.Sprint(v))
+	default:
+		b.AppendNull()
+	}
+}
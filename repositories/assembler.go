@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+/*
+ErrorPolicy - what Assembler does when enrichment fails
+*/
+type ErrorPolicy int
+
+const (
+	// PolicyFail - whole assembly fails
+	PolicyFail ErrorPolicy = iota
+	// PolicySkip - enrichment field is left out silently
+	PolicySkip
+	// PolicyPartial - enrichment field is left out and error is reported in Assembled.Errors
+	PolicyPartial
+)
+
+/*
+Enrichment - data merged into every item of parent result.
+Load gets distinct values of parent Key field and returns values by key (fmt.Sprint of key),
+result is set as Name field of item DTO
+*/
+type Enrichment struct {
+	Name   string
+	Key    string
+	Load   func(keys []interface{}) (map[string]interface{}, error)
+	Policy ErrorPolicy
+}
+
+/*
+EnrichOne - enrichment with single item of repository: item[name] = repo row where targetKey = item[key]
+*/
+func EnrichOne(name, key string, repo Recorder, targetKey string, policy ErrorPolicy) Enrichment {
+	return Enrichment{Name: name, Key: key, Policy: policy, Load: func(keys []interface{}) (map[string]interface{}, error) {
+		items, err := findIn(repo, targetKey, keys)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]interface{})
+		for _, item := range items {
+			if v, ok := fieldValue(item, targetKey); ok {
+				result[fmt.Sprint(v)] = item
+			}
+		}
+		return result, nil
+	}}
+}
+
+/*
+EnrichMany - enrichment with list of repository items: item[name] = repo rows where targetKey = item[key]
+*/
+func EnrichMany(name, key string, repo Recorder, targetKey string, policy ErrorPolicy) Enrichment {
+	return Enrichment{Name: name, Key: key, Policy: policy, Load: func(keys []interface{}) (map[string]interface{}, error) {
+		items, err := findIn(repo, targetKey, keys)
+		if err != nil {
+			return nil, err
+		}
+		groups := make(map[string][]interface{})
+		for _, item := range items {
+			if v, ok := fieldValue(item, targetKey); ok {
+				groups[fmt.Sprint(v)] = append(groups[fmt.Sprint(v)], item)
+			}
+		}
+		result := make(map[string]interface{}, len(groups))
+		for k, g := range groups {
+			result[k] = g
+		}
+		return result, nil
+	}}
+}
+
+/*
+Assembled - response DTOs. Errors - failed enrichments with PolicyPartial
+*/
+type Assembled struct {
+	Items  []map[string]interface{} `json:"items"`
+	Errors map[string]string        `json:"errors,omitempty"`
+}
+
+/*
+Assembler - composing view models: parent items are enriched with declared enrichments
+(other repositories, caches, external services) loaded concurrently
+*/
+type Assembler struct {
+	enrichments []Enrichment
+}
+
+/*
+NewAssembler - assembler constructor
+*/
+func NewAssembler(enrichments ...Enrichment) *Assembler {
+	return &Assembler{enrichments: enrichments}
+}
+
+/*
+Assemble - enriching parent Find result (collection or Result envelope).
+Items are converted to DTO maps by their json representation
+*/
+func (a *Assembler) Assemble(data interface{}) (*Assembled, error) {
+	if r, ok := data.(Result); ok {
+		data = r.Items
+	}
+	items := toSlice(data)
+	result := &Assembled{Items: make([]map[string]interface{}, len(items))}
+	for i, item := range items {
+		dto, err := toDTO(item)
+		if err != nil {
+			return nil, err
+		}
+		result.Items[i] = dto
+	}
+
+	loaded := make([]map[string]interface{}, len(a.enrichments))
+	errs := make([]error, len(a.enrichments))
+	var wg sync.WaitGroup
+	for i, e := range a.enrichments {
+		wg.Add(1)
+		go func(i int, e Enrichment) {
+			defer wg.Done()
+			loaded[i], errs[i] = e.Load(distinctKeys(items, e.Key))
+		}(i, e)
+	}
+	wg.Wait()
+
+	for i, e := range a.enrichments {
+		if errs[i] != nil {
+			switch e.Policy {
+			case PolicyFail:
+				return nil, errs[i]
+			case PolicyPartial:
+				if result.Errors == nil {
+					result.Errors = make(map[string]string)
+				}
+				result.Errors[e.Name] = errs[i].Error()
+			}
+			continue
+		}
+		for j, item := range items {
+			if key, ok := fieldValue(item, e.Key); ok {
+				if v, ok := loaded[i][fmt.Sprint(key)]; ok {
+					result.Items[j][e.Name] = v
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// findIn - finding repository items where column is one of keys
+func findIn(repo Recorder, column string, keys []interface{}) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	ints := make([]int64, 0, len(keys))
+	strs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		s := fmt.Sprint(k)
+		strs = append(strs, s)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			ints = append(ints, n)
+		}
+	}
+	q := QueryMap{column: strs}
+	if len(ints) == len(keys) {
+		q[column] = ints
+	}
+	found, err := repo.Find(q, ParamsMap{"limit": len(keys) * defaultLimit})
+	if err != nil {
+		return nil, err
+	}
+	return toSlice(found), nil
+}
+
+func distinctKeys(items []interface{}, key string) (keys []interface{}) {
+	seen := make(map[string]bool)
+	for _, item := range items {
+		v, ok := fieldValue(item, key)
+		if !ok || v == nil || seen[fmt.Sprint(v)] {
+			continue
+		}
+		seen[fmt.Sprint(v)] = true
+		keys = append(keys, v)
+	}
+	return
+}
+
+func toSlice(data interface{}) (items []interface{}) {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < rv.Len(); i++ {
+		items = append(items, rv.Index(i).Interface())
+	}
+	return
+}
+
+func toDTO(item interface{}) (map[string]interface{}, error) {
+	if m, ok := item.(map[string]interface{}); ok {
+		dto := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			dto[k] = v
+		}
+		return dto, nil
+	}
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	dto := make(map[string]interface{})
+	return dto, json.Unmarshal(b, &dto)
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+// defaultDeleteBatch - rows deleted by one statement of DeleteInBatches
+const defaultDeleteBatch = 1000
+
+/*
+DeleteInBatches - deleting rows matching query in chunks of batchSize ordered by key
+with pause between chunks, so mass deletion doesn't hold locks for long or flood WAL and replicas.
+With analyze table statistics are refreshed afterwards (Postgres ANALYZE).
+Returns number of deleted rows, also when it is interrupted by error
+*/
+func (ds *Postgres) DeleteInBatches(query QueryMap, batchSize int, pause time.Duration, analyze bool) (int64, error) {
+	if err := ds.checkWritable(); err != nil {
+		return 0, err
+	}
+	if batchSize <= 0 {
+		batchSize = defaultDeleteBatch
+	}
+	key := ds.keyColumn()
+	var deleted int64
+	var last []interface{}
+	for {
+		keys, err := ds.batchKeys(query, key, last, batchSize)
+		if err != nil || len(keys) == 0 {
+			return deleted, err
+		}
+		// query is checked again: rows could be changed after keys were selected
+		builder := ds.adapter.Builder().Delete().From(ds.source).Where(query).
+			WhereCondition(builders.And(map[string]interface{}{key: builders.In(keys)}))
+		SQL, args, err := ds.build("DeleteInBatches", builder)
+		if err != nil {
+			return deleted, err
+		}
+		result, err := ds.adapter.Exec(SQL, args...)
+		if err != nil {
+			return deleted, err
+		}
+		n, _ := result.RowsAffected()
+		deleted += n
+		ds.invalidate(query)
+		if len(keys) < batchSize {
+			break
+		}
+		last = keys[len(keys)-1:]
+		time.Sleep(pause)
+	}
+	if analyze && deleted > 0 {
+		if _, err := ds.adapter.Exec("ANALYZE " + builders.Ident(ds.adapter.Builder(), ds.source)); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// batchKeys - keys of next chunk of rows matching query after last key (keyset)
+func (ds *Postgres) batchKeys(query QueryMap, key string, last []interface{}, limit int) ([]interface{}, error) {
+	builder := ds.adapter.Builder().Select([]string{key}).From(ds.source).Where(query).
+		SeekAfter([]string{key}, last).Limit(limit, 0)
+	SQL, args, err := ds.build("DeleteInBatches", builder)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := adapters.QueryMapRows(ds.adapter, SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]interface{}, len(rows))
+	for i, row := range rows {
+		keys[i] = row[key]
+	}
+	return keys, nil
+}
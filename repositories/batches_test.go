@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestDeleteInBatchesAnalyze(t *testing.T) {
+	selected := false
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.HasPrefix(query, "SELECT") {
+			if selected {
+				return []string{"id"}, nil, nil
+			}
+			selected = true
+			return []string{"id"}, [][]driver.Value{{int64(1)}, {int64(2)}}, nil
+		}
+		if strings.HasPrefix(query, "DELETE") {
+			return nil, [][]driver.Value{{}, {}}, nil
+		}
+		return nil, nil, nil
+	})
+	repo := NewPostgres(a, "AuditLog", nil)
+	deleted, err := repo.DeleteInBatches(QueryMap{"level": "debug"}, 10, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("deleted %d", deleted)
+	}
+	if log := a.statements(); log[len(log)-1] != `ANALYZE "AuditLog"` {
+		t.Fatalf("statements %q", log)
+	}
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/niklucky/vodka/adapters"
+)
+
+const (
+	// BufferBlock - Create waits until there is space in buffer
+	BufferBlock = "block"
+	// BufferDrop - Create drops item if buffer is full
+	BufferDrop = "drop"
+
+	defaultBufferSize     = 1000
+	defaultBufferInterval = time.Second
+)
+
+// ErrBufferClosed - returned by Create after Close
+var ErrBufferClosed = errors.New("buffered repository is closed")
+
+/*
+BufferConfig - params of buffered repository
+— Size: max items waiting in memory
+— FlushSize: items count that triggers flush (Size by default)
+— Interval: max time between flushes
+— Policy: BufferBlock or BufferDrop
+— OnError: called with flush errors (errors are printed if not set)
+*/
+type BufferConfig struct {
+	Size      int
+	FlushSize int
+	Interval  time.Duration
+	Policy    string
+	OnError   func(error)
+}
+
+/*
+Buffered - repository with asynchronous writes for high-volume low-criticality data
+(metrics, request logs). Create puts item into bounded in-memory buffer,
+buffer is flushed with adapter ExecBatch by size or interval and on Close.
+Reads are passed to underlying repository
+*/
+type Buffered struct {
+	*Postgres
+	config  BufferConfig
+	items   chan interface{}
+	done    chan struct{}
+	closed  int32
+	dropped int64
+	mu      sync.RWMutex
+}
+
+/*
+NewBuffered - buffered repository constructor. Starts flushing loop
+*/
+func NewBuffered(repo *Postgres, config BufferConfig) *Buffered {
+	if config.Size <= 0 {
+		config.Size = defaultBufferSize
+	}
+	if config.FlushSize <= 0 || config.FlushSize > config.Size {
+		config.FlushSize = config.Size
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultBufferInterval
+	}
+	if config.Policy == "" {
+		config.Policy = BufferBlock
+	}
+	b := &Buffered{
+		Postgres: repo,
+		config:   config,
+		items:    make(chan interface{}, config.Size),
+		done:     make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+/*
+Create - putting item into buffer. Returns payload back, item is written later
+*/
+func (b *Buffered) Create(data interface{}) (interface{}, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if atomic.LoadInt32(&b.closed) == 1 {
+		return nil, ErrBufferClosed
+	}
+	data, _, err := b.prepareCreate(data)
+	if err != nil {
+		return nil, err
+	}
+	if b.config.Policy == BufferDrop {
+		select {
+		case b.items <- data:
+		default:
+			atomic.AddInt64(&b.dropped, 1)
+		}
+		return data, nil
+	}
+	b.items <- data
+	return data, nil
+}
+
+// Dropped - number of items dropped because buffer was full
+func (b *Buffered) Dropped() int64 {
+	return atomic.LoadInt64(&b.dropped)
+}
+
+/*
+Close - stopping accepting items and flushing buffer. Blocks until flush is done
+*/
+func (b *Buffered) Close() error {
+	if !atomic.CompareAndSwapInt32(&b.closed, 0, 1) {
+		return nil
+	}
+	// waiting for Create calls in progress
+	b.mu.Lock()
+	close(b.items)
+	b.mu.Unlock()
+	<-b.done
+	return nil
+}
+
+func (b *Buffered) loop() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+	var batch []interface{}
+	for {
+		select {
+		case item, ok := <-b.items:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) >= b.config.FlushSize {
+				b.flush(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			b.flush(batch)
+			batch = nil
+		}
+	}
+}
+
+func (b *Buffered) flush(batch []interface{}) {
+	if len(batch) == 0 {
+		return
+	}
+	var statements []adapters.Statement
+	for _, data := range batch {
+		SQL, args, err := b.adapter.Builder().Insert(b.source).Values(b.insertRow(data)).Build()
+		if err != nil {
+			b.fail(err)
+			continue
+		}
+		statements = append(statements, adapters.Statement{SQL: SQL, Args: args})
+	}
+	if b.debug {
+		fmt.Println("Buffered flush: ", len(statements), "items")
+	}
+	if err := b.adapter.ExecBatch(statements); err != nil {
+		b.fail(err)
+	}
+}
+
+func (b *Buffered) fail(err error) {
+	if b.config.OnError != nil {
+		b.config.OnError(err)
+		return
+	}
+	fmt.Println("Buffered flush error: ", err)
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import "reflect"
+
+// maxInsertParams - bind parameters limit of single statement (Postgres protocol)
+const maxInsertParams = 65535
+
+/*
+CreateMany - inserting items (maps or model structs) with multi-row INSERT ... RETURNING *,
+uuid fields are generated per row, zero key fields of structs are left to database defaults. Batches over parameters limit are inserted in chunks
+of one transaction, so either all items are created or none.
+Returns created rows mapped as Find collection
+*/
+func (ds *Postgres) CreateMany(items []interface{}) (interface{}, error) {
+	if err := ds.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return make([]int, 0), nil
+	}
+	payload := make([]interface{}, len(items))
+	columns := make(map[string]bool)
+	for i, item := range items {
+		data, _, err := ds.prepareCreate(item)
+		if err != nil {
+			return nil, err
+		}
+		payload[i] = ds.insertRow(data)
+		insertColumns(payload[i], columns)
+	}
+	// rows are inserted with union of columns (DEFAULT for missing ones)
+	chunk := maxInsertParams
+	if len(columns) > 0 {
+		chunk /= len(columns)
+	}
+	tx, err := ds.beginWrite()
+	if err != nil {
+		return nil, err
+	}
+	var created []interface{}
+	for start := 0; start < len(payload); start += chunk {
+		end := start + chunk
+		if end > len(payload) {
+			end = len(payload)
+		}
+		builder := ds.adapter.Builder().Insert(ds.source).Values(payload[start:end]).ReturnID("*")
+		SQL, args, err := ds.build("CreateMany", builder)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		rows, err := tx.Query(SQL, args...)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		result, err := ds.buildResult(rows)
+		rows.Close()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		created = append(created, result...)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ds.mapCollection(created)
+}
+
+// insertColumns - adding columns item is inserted with (map keys or struct fields) to columns
+func insertColumns(item interface{}, columns map[string]bool) {
+	if m, ok := item.(map[string]interface{}); ok {
+		for key := range m {
+			columns[key] = true
+		}
+		return
+	}
+	rv := reflect.ValueOf(item)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		for i := 0; i < rv.NumField(); i++ {
+			columns[rv.Type().Field(i).Name] = true
+		}
+	}
+}
+
+/*
+UpdateMany - updating all rows matching query with payload, returns number of updated rows
+*/
+func (ds *Postgres) UpdateMany(q QueryMap, payload map[string]interface{}) (int64, error) {
+	if err := ds.checkWritable(); err != nil {
+		return 0, err
+	}
+	if err := ds.validateColumns(payload); err != nil {
+		return 0, err
+	}
+	if err := ds.validate(payload, false); err != nil {
+		return 0, err
+	}
+	payload = ds.stampUpdate(payload)
+	SQL, args, err := ds.build("UpdateMany", ds.adapter.Builder().Update(ds.source).Set(payload).Where(q))
+	if err != nil {
+		return 0, err
+	}
+	result, err := ds.adapter.Exec(SQL, args...)
+	if err != nil {
+		return 0, err
+	}
+	ds.invalidate(q)
+	return result.RowsAffected()
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type bulkItem struct {
+	ID   int64  `db:"id"`
+	Code string `db:"code" uuid:"true"`
+	Name string `db:"name"`
+}
+
+func TestCreateManyStructs(t *testing.T) {
+	var insert string
+	var args []driver.Value
+	a := newFakeAdapter(t, func(query string, values []driver.Value) ([]string, [][]driver.Value, error) {
+		insert, args = query, values
+		return []string{"id", "code", "name"}, [][]driver.Value{{int64(1), values[0], values[1]}, {int64(2), values[2], values[3]}}, nil
+	})
+	repo := NewPostgres(a, "items", &bulkItem{})
+	result, err := repo.CreateMany([]interface{}{bulkItem{Name: "a"}, &bulkItem{Name: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zero id is left to serial default, uuid is generated per row
+	if !strings.HasPrefix(insert, "INSERT INTO items(code,name) VALUES ($1,$2),($3,$4)") {
+		t.Fatalf("insert %q", insert)
+	}
+	if len(args) != 4 || len(args[0].(string)) != 36 || args[0] == args[2] || args[1] != "a" || args[3] != "b" {
+		t.Fatalf("args %v", args)
+	}
+	items := result.([]interface{})
+	if len(items) != 2 || items[1].(bulkItem).ID != 2 {
+		t.Fatalf("created %+v", items)
+	}
+	if log := a.statements(); log[0] != "BEGIN" || log[len(log)-1] != "COMMIT" {
+		t.Fatalf("statements %q", log)
+	}
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"container/list"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/niklucky/vodka/adapters"
+)
+
+// defaultCacheSize - max number of cached items if policy Size is not set
+const defaultCacheSize = 1000
+
+// cacheEntry - cached row of FindByID
+type cacheEntry struct {
+	key     string
+	row     interface{}
+	expires time.Time
+}
+
+// entityCache - LRU of rows by id with optional TTL
+type entityCache struct {
+	mu         sync.Mutex
+	size       int
+	ttl        time.Duration
+	items      map[string]*list.Element
+	order      *list.List // front is most recently used
+	generation uint64     // changed by every invalidation
+}
+
+func newEntityCache(size int, ttl time.Duration) *entityCache {
+	return &entityCache{size: size, ttl: ttl, items: make(map[string]*list.Element), order: list.New()}
+}
+
+func (c *entityCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if c.ttl > 0 && time.Now().After(e.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.row, true
+}
+
+// set - caching row fetched at generation, skipped if there were invalidations since
+// (row could be read before concurrent write was committed)
+func (c *entityCache) set(key string, row interface{}, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	e := &cacheEntry{key: key, row: row, expires: time.Now().Add(c.ttl)}
+	if el, ok := c.items[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(e)
+	if c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.items, last.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *entityCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+func (c *entityCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+func (c *entityCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+/*
+SetCache - caching FindByID rows (LRU of policy Size, expired after TTL if set).
+Cache is invalidated by writes of repository. With policy Channel invalidations are published
+with NOTIFY and received from other instances (adapter must be adapters.Listener, e.g. Postgres).
+Cached rows are shared, so mappers must not change items in place
+*/
+func (ds *Postgres) SetCache(policy CachePolicy) error {
+	var ttl time.Duration
+	if policy.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(policy.TTL); err != nil {
+			return fmt.Errorf("cache ttl %s: %v", policy.TTL, err)
+		}
+	}
+	size := policy.Size
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	cache := newEntityCache(size, ttl)
+	if policy.Channel != "" {
+		l, ok := ds.adapter.(adapters.Listener)
+		if !ok {
+			return fmt.Errorf("cache channel %s: adapter doesn't support notifications", policy.Channel)
+		}
+		payloads, err := l.Listen(policy.Channel)
+		if err != nil {
+			return err
+		}
+		go func() {
+			for key := range payloads {
+				if key == "" {
+					cache.purge()
+				} else {
+					cache.remove(key)
+				}
+			}
+		}()
+	}
+	ds.cachePolicy = policy
+	ds.cache = cache
+	return nil
+}
+
+// fetchByID - row of FindByID from cache or storage (storage only in transaction)
+func (ds *Postgres) fetchByID(id interface{}, q QueryMap) ([]interface{}, error) {
+	// rows read in transaction could be uncommitted
+	if ds.cache == nil || ds.inTx() {
+		return ds.fetch(q, nil)
+	}
+	key := fmt.Sprint(id)
+	if row, ok := ds.cache.get(key); ok {
+		return []interface{}{row}, nil
+	}
+	generation := ds.cache.currentGeneration()
+	data, err := ds.fetch(q, nil)
+	if err == nil && len(data) > 0 {
+		ds.cache.set(key, data[0], generation)
+	}
+	return data, err
+}
+
+/*
+invalidate - dropping cached rows changed by write with query q:
+single row if it's by key, whole cache otherwise (nil q - whole cache).
+In transaction rows are dropped after commit, before it concurrent read could cache them again
+*/
+func (ds *Postgres) invalidate(q QueryMap) {
+	if ds.cache == nil {
+		return
+	}
+	name := ds.keyColumn()
+	key := ""
+	if len(q) == 1 {
+		switch id := q[name].(type) {
+		case string, int, int32, int64, uint, uint32, uint64, float64:
+			key = fmt.Sprint(id)
+		}
+	}
+	if t, ok := ds.adapter.(*adapters.Tx); ok {
+		t.AfterCommit(func() { ds.drop(t.Adapter, key) })
+		return
+	}
+	ds.drop(ds.adapter, key)
+}
+
+// drop - dropping row by key (whole cache for empty key) and publishing invalidation
+func (ds *Postgres) drop(adapter adapters.Adapter, key string) {
+	if key == "" {
+		ds.cache.purge()
+	} else {
+		ds.cache.remove(key)
+	}
+	if ds.cachePolicy.Channel == "" {
+		return
+	}
+	if _, err := adapter.Exec("SELECT pg_notify($1, $2)", ds.cachePolicy.Channel, key); err != nil {
+		// write is done, other instances keep rows until TTL
+		log.Println("Cache invalidation ("+ds.cachePolicy.Channel+"): ", err)
+	}
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestCacheInvalidatedAfterCommit(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id", "name"}, [][]driver.Value{{int64(1), "a"}}, nil
+	})
+	repo := NewPostgres(a, "items", nil)
+	if err := repo.SetCache(CachePolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindByID(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repo.cache.get("1"); !ok {
+		t.Fatal("row is not cached")
+	}
+	err := repo.WithTx(func(tx Recorder) error {
+		if _, err := tx.Update(QueryMap{"id": 1}, map[string]interface{}{"name": "b"}); err != nil {
+			return err
+		}
+		// until commit other readers see committed row
+		if _, ok := repo.cache.get("1"); !ok {
+			t.Error("row is dropped before commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repo.cache.get("1"); ok {
+		t.Fatal("row is cached after commit")
+	}
+}
+
+func TestCacheKeptOnRollback(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id", "name"}, [][]driver.Value{{int64(1), "a"}}, nil
+	})
+	repo := NewPostgres(a, "items", nil)
+	if err := repo.SetCache(CachePolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindByID(1); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := repo.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Delete(QueryMap{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repo.cache.get("1"); !ok {
+		t.Fatal("row is dropped after rollback")
+	}
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"github.com/niklucky/vodka/builders"
+)
+
+/*
+Claim - atomically taking up to limit rows matching query and updating them with payload
+(job queues). Rows locked by other workers are skipped:
+
+	UPDATE jobs SET status = 'running' WHERE id IN (SELECT id ... LIMIT n FOR UPDATE SKIP LOCKED) RETURNING *
+*/
+func (ds *Postgres) Claim(query QueryMap, payload map[string]interface{}, limit int) (interface{}, error) {
+	if err := ds.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := ds.validateColumns(payload); err != nil {
+		return nil, err
+	}
+	if err := ds.validate(payload, false); err != nil {
+		return nil, err
+	}
+	payload = ds.stampUpdate(payload)
+	key := ds.keyColumn()
+	if limit == 0 {
+		limit = 1
+	}
+	sub := ds.adapter.Builder().Select([]string{key}).From(ds.source).Where(query).Limit(limit, 0).
+		Lock(builders.LockForUpdate, builders.LockSkipLocked)
+	builder := ds.adapter.Builder().Update(ds.source).Set(payload).Where(map[string]interface{}{key: sub}).ReturnID("*")
+	SQL, args, err := ds.build("Claim", builder)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ds.invalidate(nil)
+	items, err := ds.buildResult(rows)
+	if err != nil {
+		return nil, err
+	}
+	return ds.mapCollection(items)
+}
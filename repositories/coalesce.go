@@ -0,0 +1,59 @@
+package repositories
+
+import "sync"
+
+// flight - query in progress, its result is shared by all waiting callers
+type flight struct {
+	wg     sync.WaitGroup
+	result []interface{}
+	err    error
+}
+
+// flightGroup - deduplication of concurrent identical queries (singleflight)
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do - running fn once for all concurrent callers with same key
+func (g *flightGroup) do(key string, fn func() ([]interface{}, error)) ([]interface{}, error) {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		f.wg.Wait()
+		return f.copy()
+	}
+	f := &flight{}
+	f.wg.Add(1)
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	f.result, f.err = fn()
+	f.wg.Done()
+
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+	return f.result, f.err
+}
+
+// copy - result for waiting caller. Slice is copied, but rows are shared,
+// so mappers must not change items in place
+func (f *flight) copy() ([]interface{}, error) {
+	if f.result == nil {
+		return nil, f.err
+	}
+	return append([]interface{}(nil), f.result...), f.err
+}
+
+/*
+SetCoalescing - sharing result of identical concurrent reads (Find, FindByID):
+N callers running same query while it's in progress get result of one database query
+*/
+func (ds *Postgres) SetCoalescing(enabled bool) {
+	if !enabled {
+		ds.flights = nil
+		return
+	}
+	ds.flights = &flightGroup{flights: make(map[string]*flight)}
+}
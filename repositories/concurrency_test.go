@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// passMapper - concurrent mapper returning items as they are
+type passMapper struct{}
+
+func (passMapper) Collection(items []interface{}) (interface{}, error) { return items, nil }
+func (passMapper) Item(item interface{}) (interface{}, error)          { return item, nil }
+func (passMapper) Concurrency() int                                    { return 4 }
+
+/*
+TestConcurrentReads - shared read paths (coalesced reads, FindByID cache, concurrent mapper,
+preload into coalesced rows, FindMulti) under load, run with -race
+*/
+func TestConcurrentReads(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		// slow query, so identical reads overlap and are coalesced
+		time.Sleep(time.Millisecond)
+		if strings.Contains(query, "orders") {
+			return []string{"id", "user_id"}, [][]driver.Value{{int64(10), int64(1)}, {int64(11), int64(2)}}, nil
+		}
+		return []string{"id", "name"}, [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}}, nil
+	})
+	users := NewPostgres(a, "users", &relUser{})
+	users.SetCoalescing(true)
+	users.SetMapper(passMapper{})
+	if err := users.SetCache(CachePolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	users.HasMany("orders", NewPostgres(a, "orders", &relOrder{}), "user_id")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if err := concurrentRead(users, g+i); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func concurrentRead(users *Postgres, n int) error {
+	switch n % 3 {
+	case 0:
+		result, err := users.Find(QueryMap{}, ParamsMap{"preload": "orders"})
+		if err != nil {
+			return err
+		}
+		for _, item := range result.([]interface{}) {
+			if u := item.(relUser); len(u.Orders) != 1 || u.Orders[0].UserID != u.ID {
+				return fmt.Errorf("preloaded %+v", u)
+			}
+		}
+	case 1:
+		item, err := users.FindByID(1)
+		if err != nil {
+			return err
+		}
+		if item.(relUser).ID != 1 {
+			return fmt.Errorf("found %+v", item)
+		}
+	default:
+		results, err := users.FindMulti([]QuerySpec{{Key: "a", Query: QueryMap{"id": 1}}, {Key: "b"}})
+		if err != nil {
+			return err
+		}
+		if len(results["b"].([]interface{})) != 2 {
+			return fmt.Errorf("found %+v", results)
+		}
+	}
+	return nil
+}
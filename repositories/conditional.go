@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+// defaultModifiedColumn - column with row modification time
+const defaultModifiedColumn = "updated_at"
+
+// SetModifiedColumn - column with modification time of rows for LastModified/ETag (updated_at by default)
+func (ds *Postgres) SetModifiedColumn(column string) {
+	ds.modifiedColumn = column
+}
+
+/*
+LastModified - latest modification time of rows matching query (MAX(updated_at)),
+zero time if there are no rows. Used to answer If-Modified-Since without fetching rows
+*/
+func (ds *Postgres) LastModified(query QueryMap) (time.Time, error) {
+	_, modified, err := ds.modified(query)
+	return modified, err
+}
+
+/*
+ETag - weak entity tag of Find result: hash of query, params, number of matching rows
+and their latest modification. Deleted rows change count, updated ones change modification time
+*/
+func (ds *Postgres) ETag(query QueryMap, params ParamsMap) (string, error) {
+	scoped, err := ds.applyScope(query, params)
+	if err != nil {
+		return "", err
+	}
+	count, modified, err := ds.modified(scoped)
+	if err != nil {
+		return "", err
+	}
+	// maps are printed with sorted keys, so equal queries have equal hashes
+	h := sha1.Sum([]byte(fmt.Sprintf("%s|%v|%v|%d|%d", ds.source, query, params, count, modified.UnixNano())))
+	return `W/"` + hex.EncodeToString(h[:]) + `"`, nil
+}
+
+// modified - number of rows matching query and their latest modification time
+func (ds *Postgres) modified(query QueryMap) (count int64, modified time.Time, err error) {
+	column := ds.modifiedColumn
+	if column == "" {
+		column = defaultModifiedColumn
+	}
+	builder := ds.adapter.Builder().Select([]string{builders.Count("*"), builders.Max(column)}).From(ds.source).Where(query)
+	SQL, args, err := ds.build("LastModified", builder)
+	if err != nil {
+		return
+	}
+	row, err := ds.adapter.QueryRow(SQL, args...)
+	if err != nil {
+		return
+	}
+	var last sql.NullTime
+	if err = row.Scan(&count, &last); err != nil {
+		return
+	}
+	return count, last.Time, nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import "context"
+
+/*
+ContextMapper - mapper with access to call context (auth, tenant, locale).
+Used instead of Mapper methods when repository mapper implements it
+*/
+type ContextMapper interface {
+	CollectionContext(context.Context, []interface{}) (interface{}, error)
+	ItemContext(context.Context, interface{}) (interface{}, error)
+}
+
+/*
+WithContext - copy of repository passing ctx to ContextMapper (and lifecycle hooks)
+*/
+func (ds *Postgres) WithContext(ctx context.Context) *Postgres {
+	c := *ds
+	c.ctx = ctx
+	return &c
+}
+
+// Context - context of repository calls, context.Background() if not set
+func (ds *Postgres) Context() context.Context {
+	if ds.ctx != nil {
+		return ds.ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type uuidItem struct {
+	ID   string `db:"id" uuid:"true" key:"true"`
+	Name string `db:"name"`
+}
+
+func TestCreateStructWithUUID(t *testing.T) {
+	var inserted []driver.Value
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.HasPrefix(query, "INSERT") {
+			inserted = args
+			return nil, nil, nil
+		}
+		return []string{"id", "name"}, [][]driver.Value{{args[0], "ann"}}, nil
+	})
+	repo := NewPostgres(a, "items", &uuidItem{})
+	for _, payload := range []interface{}{uuidItem{Name: "ann"}, &uuidItem{Name: "ann"}} {
+		item, err := repo.Create(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created := item.(uuidItem)
+		if len(created.ID) != 36 || created.Name != "ann" {
+			t.Fatalf("created %+v", created)
+		}
+		if len(inserted) != 2 {
+			t.Fatalf("inserted %v", inserted)
+		}
+	}
+	// map payload of caller is not changed
+	payload := map[string]interface{}{"name": "ann"}
+	if _, err := repo.Create(payload); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := payload["id"]; ok {
+		t.Fatalf("payload is changed: %v", payload)
+	}
+}
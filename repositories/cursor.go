@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/niklucky/vodka"
+)
+
+/*
+SetCursorKey - columns FindCursor pages are ordered by (primary key by default).
+Key columns are appended if they are not among them, so order is stable for rows with equal values
+*/
+func (ds *Postgres) SetCursorKey(columns ...string) {
+	ds.cursorKeys = columns
+}
+
+/*
+FindCursor - page of items matching query after cursor (empty cursor is the first page)
+and opaque cursor of the next page, empty if it is the last one.
+Pages are ordered by cursor key (SetCursorKey) with keyset condition, so they are stable
+when rows are inserted or deleted between requests
+*/
+func (ds *Postgres) FindCursor(query QueryMap, cursor string, limit int) (interface{}, string, error) {
+	if err := ds.checkAllowed(query, nil); err != nil {
+		return nil, "", err
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	columns := ds.cursorColumns()
+	last, err := decodeCursor(cursor, len(columns))
+	if err != nil {
+		return nil, "", err
+	}
+	// one extra row tells if there is next page
+	builder := ds.selectBuilder(query, QueryModificator{limit: limit + 1}).SeekAfter(columns, last)
+	SQL, args, err := ds.build("FindCursor", builder)
+	if err != nil {
+		return nil, "", err
+	}
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	data, err := ds.buildResult(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	var next string
+	if len(data) > limit {
+		data = data[:limit]
+		if next, err = ds.encodeCursor(data[limit-1], columns); err != nil {
+			return nil, "", err
+		}
+	}
+	result, err := ds.mapCollection(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if d, ok := result.([]interface{}); ok && len(d) == 0 {
+		result = make([]int, 0)
+	}
+	result, err = ds.applyProfile(ds.profile, result)
+	return result, next, err
+}
+
+// cursorColumns - cursor key columns ending with primary key columns
+func (ds *Postgres) cursorColumns() []string {
+	columns := append([]string(nil), ds.cursorKeys...)
+	for _, key := range ds.keyColumns() {
+		if !inArray(key, columns) {
+			columns = append(columns, key)
+		}
+	}
+	return columns
+}
+
+// encodeCursor - cursor of position after row: base64 JSON of its cursor key values
+func (ds *Postgres) encodeCursor(row interface{}, columns []string) (string, error) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		v, ok := fieldValue(row, column)
+		if !ok {
+			return "", vodka.NewServerError("unknown_key", "Cursor key "+column+" is not found in "+ds.source+" item")
+		}
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		values[i] = v
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", vodka.NewServerError("invalid_cursor", err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor - key values of cursor position, nil for empty cursor.
+// Numbers are kept as json.Number, so big ids don't lose precision
+func decodeCursor(cursor string, n int) ([]interface{}, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, vodka.NewBadRequestError("invalid_cursor", "Cursor is malformed")
+	}
+	var values []interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&values); err != nil || len(values) != n {
+		return nil, vodka.NewBadRequestError("invalid_cursor", "Cursor is malformed")
+	}
+	return values, nil
+}
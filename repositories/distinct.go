@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+/*
+DistinctValue - distinct value of column with number of matching rows (if counted)
+*/
+type DistinctValue struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count,omitempty"`
+}
+
+/*
+DistinctValues - distinct values of column matching query (for dropdowns and filters).
+With counts values are ordered by number of rows, otherwise by value. limit 0 - defaultLimit
+*/
+func (ds *Postgres) DistinctValues(column string, query QueryMap, limit int, counts bool) ([]DistinctValue, error) {
+	if err := ds.checkAllowed(query, nil); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	builder := ds.adapter.Builder()
+	if counts {
+		builder = builder.Select([]string{column + " AS value", builders.As(builders.Count("*"), "count")}).
+			GroupBy([]string{column}).
+			Order(builders.OrderParam{OrderBy: builders.Count("*"), Desc: true})
+	} else {
+		builder = builder.Select([]string{column + " AS value"}).
+			Distinct().
+			Order(builders.OrderParam{OrderBy: column, Asc: true})
+	}
+	SQL, args, err := ds.build("DistinctValues", builder.From(ds.source).Where(query).Limit(limit, 0))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := adapters.QueryMapRows(ds.adapter, SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]DistinctValue, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, DistinctValue{Value: row["value"], Count: getInt64(row["count"])})
+	}
+	return values, nil
+}
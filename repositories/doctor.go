@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	lib "github.com/niklucky/go-lib"
+	"github.com/niklucky/vodka"
+	"github.com/niklucky/vodka/adapters"
+)
+
+/*
+Diagnose - startup checks of repository for vodka.Doctor: connectivity, columns of model
+present in source, indexes on join keys of declared relations and declared indexes (Postgres)
+*/
+func (ds *Postgres) Diagnose(ctx context.Context) []vodka.Check {
+	component := "repository " + ds.source
+	check := func(name string, err error, problems []string) vodka.Check {
+		c := vodka.Check{Component: component, Name: name, OK: err == nil && len(problems) == 0}
+		if err != nil {
+			c.Message = err.Error()
+		} else if len(problems) > 0 {
+			c.Message = strings.Join(problems, "; ")
+		}
+		return c
+	}
+	if _, err := ds.queryValue(ctx, "SELECT 1"); err != nil {
+		return []vodka.Check{check("connection", err, nil)}
+	}
+	checks := []vodka.Check{check("connection", nil, nil)}
+	problems, err := ds.schemaProblems(ctx)
+	checks = append(checks, check("schema", err, problems))
+	problems, err = ds.relationProblems(ctx)
+	checks = append(checks, check("relation indexes", err, problems))
+	drift, err := ds.CheckIndexes()
+	problems = nil
+	for _, d := range drift {
+		problems = append(problems, d.Index.Name+": "+d.Problem)
+	}
+	return append(checks, check("indexes", err, problems))
+}
+
+// schemaProblems - columns of model (or whole source in dynamic mode) missing in database
+func (ds *Postgres) schemaProblems(ctx context.Context) ([]string, error) {
+	schema, table := splitSource(ds.source)
+	rows, err := ds.queryContext(ctx, `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2`, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return []string{"source " + ds.source + " doesn't exist"}, nil
+	}
+	if ds.model == nil {
+		return nil, nil
+	}
+	var missing []string
+	for _, column := range lib.GetStructTags(reflect.ValueOf(ds.model).Elem(), "db", true) {
+		if !existing[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	sort.Strings(missing)
+	return []string{"missing columns: " + strings.Join(missing, ", ")}, nil
+}
+
+// relationProblems - joined sources without index starting with join key
+func (ds *Postgres) relationProblems(ctx context.Context) ([]string, error) {
+	var problems []string
+	for _, j := range ds.joinedRepositories {
+		if j.Key == "" {
+			continue
+		}
+		indexed, err := ds.queryValue(ctx, `SELECT EXISTS (SELECT 1 FROM pg_index i
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = i.indkey[0]
+			WHERE i.indrelid = to_regclass($1) AND a.attname = $2)`, j.Source, j.Key)
+		if err != nil {
+			return nil, err
+		}
+		if indexed != true {
+			problems = append(problems, "no index on "+j.Source+"("+j.Key+")")
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+/*
+Extensions - vodka.Diagnoser checking that Postgres extensions (e.g. pgcrypto, pg_trgm)
+are installed in database
+*/
+func Extensions(adapter adapters.Adapter, names ...string) vodka.Diagnoser {
+	ds := &Postgres{adapter: adapter}
+	return vodka.DiagnoserFunc(func(ctx context.Context) []vodka.Check {
+		checks := make([]vodka.Check, 0, len(names))
+		for _, name := range names {
+			c := vodka.Check{Component: "extension " + name, Name: "installed"}
+			version, err := ds.queryValue(ctx, "SELECT installed_version FROM pg_available_extensions WHERE name = $1", name)
+			switch {
+			case err == sql.ErrNoRows:
+				c.Message = "not available on server"
+			case err != nil:
+				c.Message = err.Error()
+			case version == nil:
+				c.Message = "available, but not installed (CREATE EXTENSION " + name + ")"
+			default:
+				c.OK = true
+				c.Message = fmt.Sprintf("version %s", version)
+			}
+			checks = append(checks, c)
+		}
+		return checks
+	})
+}
+
+// queryContext - running query with ctx if adapter supports it
+func (ds *Postgres) queryContext(ctx context.Context, SQL string, args ...interface{}) (*sql.Rows, error) {
+	if a, ok := ds.adapter.(adapters.ContextAdapter); ok {
+		return a.QueryContext(ctx, SQL, args...)
+	}
+	return ds.adapter.Query(SQL, args...)
+}
+
+// queryValue - first column of first row, sql.ErrNoRows if there are no rows
+func (ds *Postgres) queryValue(ctx context.Context, SQL string, args ...interface{}) (interface{}, error) {
+	rows, err := ds.queryContext(ctx, SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+	var value interface{}
+	if err := rows.Scan(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// splitSource - schema (public by default) and table of source
+func splitSource(source string) (schema, table string) {
+	if i := strings.Index(source, "."); i != -1 {
+		return source[:i], source[i+1:]
+	}
+	return "public", source
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/niklucky/vodka"
+	"github.com/niklucky/vodka/adapters"
+)
+
+/*
+NewDynamic - repository without Go model (schema-less mode).
+Columns and primary key are discovered from information_schema,
+results are returned as maps with values converted by column types
+and payloads of Create/Update are validated against live schema
+*/
+func NewDynamic(adapter adapters.Adapter, source string) (*Postgres, error) {
+	ds := NewPostgres(adapter, source, nil)
+	if err := ds.describe(); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// describe - loading columns and primary key of source
+func (ds *Postgres) describe() error {
+	schema, table := splitSource(ds.source)
+	rows, err := ds.adapter.Query(`SELECT column_name, data_type FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2`, schema, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return err
+		}
+		columns[name] = dataType
+	}
+	if len(columns) == 0 {
+		return vodka.NewServerError("source_not_found", "Source "+ds.source+" has no columns")
+	}
+	ds.columns = columns
+
+	keys, err := ds.adapter.Query(`SELECT k.column_name FROM information_schema.table_constraints c
+		JOIN information_schema.key_column_usage k
+		ON k.constraint_name = c.constraint_name AND k.table_schema = c.table_schema
+		WHERE c.constraint_type = 'PRIMARY KEY' AND c.table_schema = $1 AND c.table_name = $2
+		ORDER BY k.ordinal_position`, schema, table)
+	if err != nil {
+		return err
+	}
+	defer keys.Close()
+	ds.keys = nil
+	for keys.Next() {
+		var key string
+		if err := keys.Scan(&key); err != nil {
+			return err
+		}
+		ds.keys = append(ds.keys, key)
+	}
+	return keys.Err()
+}
+
+// validateColumns - checking payload keys against discovered columns (dynamic mode only)
+func (ds *Postgres) validateColumns(payload map[string]interface{}) error {
+	if ds.columns == nil {
+		return nil
+	}
+	var unknown []string
+	for key := range payload {
+		if _, ok := ds.columns[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return vodka.NewBadRequestError("unknown_columns", unknown)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import "errors"
+
+// ErrStop - returned by FindEach callback to stop iteration without error
+var ErrStop = errors.New("stop iteration")
+
+/*
+FindEach - streaming Find: rows are scanned lazily and every mapped item is passed to fn,
+so memory stays flat for exports and batch jobs. Params are the same as in Find,
+but rows are not limited unless params["limit"] is set.
+Iteration stops on first fn error (returned, except ErrStop)
+*/
+func (ds *Postgres) FindEach(query QueryMap, params ParamsMap, fn func(interface{}) error) error {
+	if err := ds.checkAllowed(query, params); err != nil {
+		return err
+	}
+	query, err := ds.applyScope(query, params)
+	if err != nil {
+		return err
+	}
+	mod := parseParams(params)
+	builder := ds.selectBuilder(query, mod)
+	if mod.limit == 0 {
+		builder = builder.Limit(0, mod.skip)
+	}
+	SQL, args, err := ds.build("FindEach", builder)
+	if err != nil {
+		return err
+	}
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	profile := ds.profile
+	if p, ok := params["profile"].(string); ok {
+		profile = p
+	}
+	err = ds.scanRows(rows, func(row interface{}) error {
+		item, err := ds.mapItem(row)
+		if err != nil {
+			return err
+		}
+		if item, err = ds.applyProfile(profile, item); err != nil {
+			return err
+		}
+		return fn(item)
+	})
+	if err == ErrStop {
+		return nil
+	}
+	return err
+}
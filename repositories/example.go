@@ -0,0 +1,40 @@
+package repositories
+
+import "reflect"
+
+/*
+QueryOf - query by partially filled model struct (query-by-example): non-zero fields
+with `db` tag become equality conditions. Pointer fields are used if not nil,
+so zero values could be matched too:
+
+	repo.Find(repositories.QueryOf(User{Status: "active", OrgID: 5}), nil)
+	repo.Update(repositories.QueryOf(&User{ID: id}), payload)
+*/
+func QueryOf(example interface{}) QueryMap {
+	q := make(QueryMap)
+	rv := reflect.ValueOf(example)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return q
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		column := t.Field(i).Tag.Get("db")
+		if column == "" || column == "-" || t.Field(i).PkgPath != "" {
+			continue
+		}
+		field := rv.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if !field.IsNil() {
+				q[column] = field.Elem().Interface()
+			}
+			continue
+		}
+		if !field.IsZero() {
+			q[column] = field.Interface()
+		}
+	}
+	return q
+}
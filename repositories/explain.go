@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+Plan - node of query plan (EXPLAIN FORMAT JSON). Actual* fields are set with analyze
+*/
+type Plan struct {
+	NodeType        string  `json:"Node Type"`
+	Relation        string  `json:"Relation Name,omitempty"`
+	Index           string  `json:"Index Name,omitempty"`
+	StartupCost     float64 `json:"Startup Cost"`
+	TotalCost       float64 `json:"Total Cost"`
+	PlanRows        int64   `json:"Plan Rows"`
+	ActualTotalTime float64 `json:"Actual Total Time,omitempty"`
+	ActualRows      int64   `json:"Actual Rows,omitempty"`
+	Plans           []Plan  `json:"Plans,omitempty"`
+}
+
+/*
+Explained - parsed EXPLAIN output. Times are in milliseconds (set with analyze)
+*/
+type Explained struct {
+	Plan          Plan    `json:"Plan"`
+	PlanningTime  float64 `json:"Planning Time,omitempty"`
+	ExecutionTime float64 `json:"Execution Time,omitempty"`
+}
+
+/*
+Explain - plan of Find query with params. With params["analyze"] = true query is executed
+(EXPLAIN ANALYZE) and actual rows and times are reported
+*/
+func (ds *Postgres) Explain(query QueryMap, params ParamsMap) (Explained, error) {
+	analyze := params["analyze"] == true
+	SQL, args, err := ds.build("Explain", ds.selectBuilder(query, parseParams(params)).Explain(analyze))
+	if err != nil {
+		return Explained{}, err
+	}
+	return ds.queryPlan(SQL, args)
+}
+
+// queryPlan - running EXPLAIN (FORMAT JSON) statement and parsing plan
+func (ds *Postgres) queryPlan(SQL string, args []interface{}) (e Explained, err error) {
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	var plan []byte
+	if rows.Next() {
+		if err = rows.Scan(&plan); err != nil {
+			return
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+	var explained []Explained
+	if err = json.Unmarshal(plan, &explained); err != nil {
+		return
+	}
+	if len(explained) == 0 {
+		return e, fmt.Errorf("explain: empty plan")
+	}
+	return explained[0], nil
+}
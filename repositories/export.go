@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"io"
+
+	"github.com/niklucky/vodka"
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+/*
+ExportCSV - writing rows by query and params (same as Find) into w as CSV with header.
+Rows are streamed by database with COPY (SELECT ...) TO STDOUT instead of being scanned
+one by one, so adapter has to be adapters.Copier (Postgres). Returns number of rows
+*/
+func (ds *Postgres) ExportCSV(w io.Writer, query QueryMap, params interface{}) (int64, error) {
+	copier, ok := ds.adapter.(adapters.Copier)
+	if !ok {
+		return 0, vodka.NewServerError("export_not_supported", "Adapter of "+ds.source+" doesn't support COPY")
+	}
+	SQL, args, err := ds.build("ExportCSV", ds.selectBuilder(query, parseParams(params)))
+	if err != nil {
+		return 0, err
+	}
+	// COPY has no parameters
+	if SQL, err = builders.Interpolate(SQL, args); err != nil {
+		return 0, vodka.NewServerError("invalid_query", err.Error())
+	}
+	return copier.CopyTo(w, "COPY ("+SQL+") TO STDOUT WITH (FORMAT csv, HEADER)")
+}
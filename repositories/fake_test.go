@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+// fakeHandler - result of statement: columns and rows for queries (nil for exec)
+type fakeHandler func(query string, args []driver.Value) ([]string, [][]driver.Value, error)
+
+// fakeDBs - handlers of opened fake databases by DSN
+var (
+	fakeDBs  sync.Map
+	fakeSeq  int64
+	fakeOnce sync.Once
+)
+
+/*
+fakeAdapter - adapter over database/sql with fake driver. Statements are logged
+with transaction state, so tests can check which ones ran in tx
+*/
+type fakeAdapter struct {
+	db  *sql.DB
+	mu  sync.Mutex
+	log []string
+}
+
+func newFakeAdapter(t testing.TB, handler fakeHandler) *fakeAdapter {
+	fakeOnce.Do(func() { sql.Register("vodkafake", fakeDriver{}) })
+	a := &fakeAdapter{}
+	dsn := fmt.Sprint("fake", atomic.AddInt64(&fakeSeq, 1))
+	fakeDBs.Store(dsn, &fakeDB{adapter: a, handler: handler})
+	db, err := sql.Open("vodkafake", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	a.db = db
+	return a
+}
+
+func (a *fakeAdapter) record(s string) {
+	a.mu.Lock()
+	a.log = append(a.log, s)
+	a.mu.Unlock()
+}
+
+// statements - logged statements, prefixed with "tx: " if they ran in transaction
+func (a *fakeAdapter) statements() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.log...)
+}
+
+func (a *fakeAdapter) Connect() error { return nil }
+func (a *fakeAdapter) Exec(q string, args ...interface{}) (sql.Result, error) {
+	return a.db.Exec(q, args...)
+}
+func (a *fakeAdapter) QueryRow(q string, args ...interface{}) (*sql.Row, error) {
+	return a.db.QueryRow(q, args...), nil
+}
+func (a *fakeAdapter) Query(q string, args ...interface{}) (*sql.Rows, error) {
+	return a.db.Query(q, args...)
+}
+func (a *fakeAdapter) ExecBatch(statements []adapters.Statement) error {
+	for _, s := range statements {
+		if _, err := a.db.Exec(s.SQL, s.Args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (a *fakeAdapter) Begin() (*sql.Tx, error) { return a.db.Begin() }
+func (a *fakeAdapter) Builder() builders.Builder {
+	b, _ := builders.New("postgres")
+	return b
+}
+
+type fakeDriver struct{}
+
+type fakeDB struct {
+	adapter *fakeAdapter
+	handler fakeHandler
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	db, ok := fakeDBs.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown dsn %s", dsn)
+	}
+	return &fakeConn{db: db.(*fakeDB)}, nil
+}
+
+type fakeConn struct {
+	db   *fakeDB
+	inTx bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	c.db.adapter.record("BEGIN")
+	return c, nil
+}
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Begin()
+}
+func (c *fakeConn) Commit() error {
+	c.inTx = false
+	c.db.adapter.record("COMMIT")
+	return nil
+}
+func (c *fakeConn) Rollback() error {
+	c.inTx = false
+	c.db.adapter.record("ROLLBACK")
+	return nil
+}
+
+func (c *fakeConn) run(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+	if c.inTx {
+		c.db.adapter.record("tx: " + query)
+	} else {
+		c.db.adapter.record(query)
+	}
+	if c.db.handler == nil {
+		return nil, nil, nil
+	}
+	return c.db.handler(query, args)
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	_, rows, err := s.conn.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(rows)), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	columns, rows, err := s.conn.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	i       int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
+
+// hasStatement - true if some logged statement starts with prefix
+func hasStatement(statements []string, prefix string) bool {
+	for _, s := range statements {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
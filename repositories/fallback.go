@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/niklucky/vodka"
+)
+
+// maxSnapshots - max number of distinct reads remembered as last-known snapshots
+const maxSnapshots = 1000
+
+/*
+StaleResult - result of FallbackRecorder read served from secondary source
+*/
+type StaleResult struct {
+	Data  interface{} `json:"data"`
+	Stale bool        `json:"stale"`
+	Error string      `json:"error,omitempty"`
+}
+
+/*
+FallbackRecorder - repository decorator for graceful degradation.
+Reads go to primary; when primary fails (or circuit is open after Threshold
+consecutive failures, for Cooldown) they are served from secondary repository
+(cache, replica) or, if secondary is nil, from last-known snapshot of successful reads.
+Fallback results are wrapped into StaleResult. Writes always go to primary
+*/
+type FallbackRecorder struct {
+	primary   Recorder
+	secondary Recorder
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openedAt  time.Time
+	snapshots map[string]interface{}
+}
+
+/*
+NewFallback - fallback recorder constructor
+*/
+func NewFallback(primary, secondary Recorder, threshold int, cooldown time.Duration) *FallbackRecorder {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &FallbackRecorder{
+		primary:   primary,
+		secondary: secondary,
+		threshold: threshold,
+		cooldown:  cooldown,
+		snapshots: make(map[string]interface{}),
+	}
+}
+
+// Join - joining source to both primary and secondary
+func (f *FallbackRecorder) Join(source, key, targetKey, joinType string, fields []string) {
+	f.primary.Join(source, key, targetKey, joinType, fields)
+	if f.secondary != nil {
+		f.secondary.Join(source, key, targetKey, joinType, fields)
+	}
+}
+
+// Find - finding in primary with fallback
+func (f *FallbackRecorder) Find(query QueryMap, params ParamsMap) (interface{}, error) {
+	key := fmt.Sprintf("find:%v:%v", query, params)
+	return f.read(key, func(r Recorder) (interface{}, error) {
+		return r.Find(query, params)
+	})
+}
+
+// FindByID - finding by id in primary with fallback
+func (f *FallbackRecorder) FindByID(id interface{}) (interface{}, error) {
+	key := fmt.Sprintf("id:%v", id)
+	return f.read(key, func(r Recorder) (interface{}, error) {
+		return r.FindByID(id)
+	})
+}
+
+// Count - counting in primary with fallback (count of secondary is returned unwrapped)
+func (f *FallbackRecorder) Count(query QueryMap) (int64, error) {
+	key := fmt.Sprintf("count:%v", query)
+	result, err := f.read(key, func(r Recorder) (interface{}, error) {
+		return r.Count(query)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if stale, ok := result.(StaleResult); ok {
+		result = stale.Data
+	}
+	count, _ := result.(int64)
+	return count, nil
+}
+
+// Create - creating in primary
+func (f *FallbackRecorder) Create(data interface{}) (interface{}, error) {
+	return f.primary.Create(data)
+}
+
+// Upsert - upserting in primary
+func (f *FallbackRecorder) Upsert(data interface{}, conflictColumns []string) (interface{}, error) {
+	return f.primary.Upsert(data, conflictColumns)
+}
+
+// Delete - deleting in primary
+func (f *FallbackRecorder) Delete(q QueryMap) (interface{}, error) {
+	return f.primary.Delete(q)
+}
+
+// DeleteByID - deleting in primary
+func (f *FallbackRecorder) DeleteByID(id interface{}) (interface{}, error) {
+	return f.primary.DeleteByID(id)
+}
+
+// Update - updating in primary
+func (f *FallbackRecorder) Update(q QueryMap, payload map[string]interface{}) (interface{}, error) {
+	return f.primary.Update(q, payload)
+}
+
+// IsOpen - circuit is open: primary is skipped until cooldown passes
+func (f *FallbackRecorder) IsOpen() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.isOpen()
+}
+
+func (f *FallbackRecorder) isOpen() bool {
+	return f.failures >= f.threshold && time.Since(f.openedAt) < f.cooldown
+}
+
+func (f *FallbackRecorder) read(key string, fn func(Recorder) (interface{}, error)) (interface{}, error) {
+	var primaryErr error
+	if !f.IsOpen() {
+		result, err := fn(f.primary)
+		if err == nil || isClientError(err) {
+			f.success(key, result, err)
+			return result, err
+		}
+		f.failure()
+		primaryErr = err
+	} else {
+		primaryErr = vodka.NewServerError("circuit_open", "Primary source is unavailable")
+	}
+	stale := StaleResult{Stale: true, Error: primaryErr.Error()}
+	if f.secondary != nil {
+		result, err := fn(f.secondary)
+		if err != nil {
+			return nil, primaryErr
+		}
+		stale.Data = result
+		return stale, nil
+	}
+	f.mu.Lock()
+	snapshot, ok := f.snapshots[key]
+	f.mu.Unlock()
+	if !ok {
+		return nil, primaryErr
+	}
+	stale.Data = snapshot
+	return stale, nil
+}
+
+func (f *FallbackRecorder) success(key string, result interface{}, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = 0
+	if f.secondary != nil || err != nil {
+		return
+	}
+	if _, ok := f.snapshots[key]; ok || len(f.snapshots) < maxSnapshots {
+		f.snapshots[key] = result
+	}
+}
+
+func (f *FallbackRecorder) failure() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures++
+	if f.failures >= f.threshold {
+		f.openedAt = time.Now()
+	}
+}
+
+// isClientError - errors like 404 are valid answers of primary and shouldn't trigger fallback
+func isClientError(err error) bool {
+	e, ok := err.(vodka.Error)
+	return ok && e.HTTPCode() >= 400 && e.HTTPCode() < 500
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+)
+
+// injectionKeys - seed corpus of FuzzQueryMapKeys and FuzzOrderBy
+var injectionKeys = []string{
+	"id",
+	"t.id",
+	"age >=",
+	"name ILIKE",
+	"status NOT IN",
+	"data->>age>=",
+	"-created_at nulls last",
+	"name asc, id desc",
+	`"Name"`,
+	"id=1/**/OR/**/(1)=",
+	"(SELECT/**/pg_sleep(10))",
+	"id; DROP TABLE users; --",
+	"id OR 1=1 --",
+	"data->>'x' OR 1=1",
+	"name) UNION SELECT password FROM users --",
+}
+
+// forbiddenWords - words that can't be rendered by key or orderBy of request outside quotes
+var forbiddenWords = map[string]bool{"SELECT": true, "UNION": true, "OR": true, "AND": true, "FROM": true, "WHERE": true, "DROP": true}
+
+/*
+FuzzQueryMapKeys - key of QueryMap can't change structure of query:
+query is rejected or its WHERE has single predicate with bound value
+
+	go test ./repositories -fuzz FuzzQueryMapKeys
+*/
+func FuzzQueryMapKeys(f *testing.F) {
+	repo := NewPostgres(newFakeAdapter(f, nil), "users", nil)
+	for _, key := range injectionKeys {
+		f.Add(key)
+	}
+	f.Fuzz(func(t *testing.T, key string) {
+		SQL, _, err := repo.selectBuilder(QueryMap{key: 1}, QueryModificator{}).Build()
+		if err != nil {
+			return
+		}
+		where := clause(t, SQL, " WHERE ", " LIMIT ")
+		if strings.Count(where, "$") != 1 {
+			t.Fatalf("key %q: value is not single bound argument: %s", key, SQL)
+		}
+		checkClause(t, key, strings.Replace(where, "($1)", "$1", 1), SQL)
+	})
+}
+
+/*
+FuzzOrderBy - orderBy param can't render expression into ORDER BY
+
+	go test ./repositories -fuzz FuzzOrderBy
+*/
+func FuzzOrderBy(f *testing.F) {
+	repo := NewPostgres(newFakeAdapter(f, nil), "users", nil)
+	for _, key := range injectionKeys {
+		f.Add(key)
+	}
+	f.Fuzz(func(t *testing.T, orderBy string) {
+		mod := parseParams(ParamsMap{"orderBy": orderBy})
+		SQL, _, err := repo.selectBuilder(QueryMap{}, mod).Build()
+		if err != nil || len(mod.orderBy) == 0 {
+			return
+		}
+		checkClause(t, orderBy, clause(t, SQL, " ORDER BY ", " LIMIT "), SQL)
+	})
+}
+
+// clause - part of SQL between start and end markers with literals and quoted identifiers removed
+func clause(t *testing.T, SQL, start, end string) string {
+	stripped := stripQuoted(t, SQL)
+	i := strings.Index(stripped, start)
+	if i == -1 {
+		t.Fatalf("no %q in %s", start, SQL)
+	}
+	part := stripped[i+len(start):]
+	if j := strings.Index(part, end); j != -1 {
+		part = part[:j]
+	}
+	return part
+}
+
+func checkClause(t *testing.T, input, part, SQL string) {
+	if strings.ContainsAny(part, "();") || strings.Contains(part, "--") || strings.Contains(part, "/*") {
+		t.Fatalf("input %q rendered expression: %s", input, SQL)
+	}
+	for _, word := range strings.FieldsFunc(part, func(r rune) bool { return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') }) {
+		if forbiddenWords[strings.ToUpper(word)] {
+			t.Fatalf("input %q rendered %s: %s", input, word, SQL)
+		}
+	}
+}
+
+// stripQuoted - SQL with string literals and quoted identifiers replaced by "?"
+func stripQuoted(t *testing.T, SQL string) string {
+	var out strings.Builder
+	for i := 0; i < len(SQL); i++ {
+		c := SQL[i]
+		if c != '\'' && c != '"' {
+			out.WriteByte(c)
+			continue
+		}
+		for i++; ; i++ {
+			if i >= len(SQL) {
+				t.Fatalf("unterminated quote: %s", SQL)
+			}
+			if SQL[i] == c {
+				if i+1 < len(SQL) && SQL[i+1] == c {
+					i++
+					continue
+				}
+				break
+			}
+		}
+		out.WriteString("?")
+	}
+	return out.String()
+}
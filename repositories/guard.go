@@ -0,0 +1,63 @@
+package repositories
+
+import "github.com/niklucky/vodka"
+
+const (
+	// GuardReject - rejecting expensive Find with 400
+	GuardReject = "reject"
+	// GuardLimit - running expensive Find with limit reduced proportionally to estimate
+	GuardLimit = "limit"
+)
+
+/*
+CostGuard - pre-flight check of Find queries with EXPLAIN (Postgres).
+Query is guarded if planner estimated total cost is over MaxCost or rows are over MaxRows
+(zero is no threshold). Action is GuardReject (default) or GuardLimit
+*/
+type CostGuard struct {
+	MaxCost float64 `json:"maxCost" yaml:"maxCost"`
+	MaxRows int64   `json:"maxRows" yaml:"maxRows"`
+	Action  string  `json:"action" yaml:"action"`
+}
+
+// SetCostGuard - checking estimated cost of every Find before running it
+func (ds *Postgres) SetCostGuard(guard CostGuard) {
+	ds.costGuard = &guard
+}
+
+// checkCost - limit query could be run with (reduced with GuardLimit)
+// or error if it is too expensive
+func (ds *Postgres) checkCost(SQL string, args []interface{}, limit int) (int, error) {
+	g := ds.costGuard
+	explained, err := ds.queryPlan("EXPLAIN (FORMAT JSON) "+SQL, args)
+	if err != nil {
+		return limit, err
+	}
+	e := explained.Plan
+	ratio := 1.0
+	if g.MaxCost > 0 && e.TotalCost > g.MaxCost {
+		ratio = g.MaxCost / e.TotalCost
+	}
+	if g.MaxRows > 0 && e.PlanRows > g.MaxRows {
+		if r := float64(g.MaxRows) / float64(e.PlanRows); r < ratio {
+			ratio = r
+		}
+	}
+	if ratio == 1 {
+		return limit, nil
+	}
+	if g.Action != GuardLimit {
+		return limit, vodka.NewBadRequestError("query_too_expensive", map[string]interface{}{
+			"cost": e.TotalCost,
+			"rows": e.PlanRows,
+		})
+	}
+	// cost of sorted or aggregated queries doesn't go down with limit, so it's best effort
+	if reduced := int(float64(limit) * ratio); reduced < limit {
+		limit = reduced
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return limit, nil
+}
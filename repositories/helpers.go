@@ -4,9 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
-
-	"github.com/niklucky/vodka/builders"
 )
 
 func populateStructByMap(rv reflect.Value, data map[string]interface{}) interface{} {
@@ -23,36 +22,102 @@ func populateStructByMap(rv reflect.Value, data map[string]interface{}) interfac
 		}
 		// fmt.Println("key: ", key)
 		if v, ok := data[key]; ok {
+			// fmt.Printf("v: %T, %s\n", v, st.Field(i).Type().String())
+			field := st.Field(i)
 			if v == nil {
+				// NULL is zero value (nil for pointer fields)
+				field.Set(reflect.Zero(field.Type()))
 				continue
 			}
-			// fmt.Printf("v: %T, %s\n", v, st.Field(i).Type().String())
-			switch st.Field(i).Type().String() {
-			case "int64":
-				st.Field(i).SetInt(getInt64(v))
-			case "float64":
-				st.Field(i).SetFloat(getFloat64(v))
-			case "string":
-				st.Field(i).SetString(fmt.Sprintf("%v", v))
-			case "bool":
-				st.Field(i).SetBool(getBool(v))
-			case "time.Time":
-				st.Field(i).Set(reflect.ValueOf(getTime(v)))
+			if field.Kind() == reflect.Ptr {
+				ptr := reflect.New(field.Type().Elem())
+				if setField(ptr.Elem(), v) {
+					field.Set(ptr)
+				}
+				continue
 			}
+			setField(field, v)
 		}
 	}
 	return st.Interface()
 }
 
+// payloadMap - copy of payload as map: map itself or struct fields by `db` tag (field name if tag is empty, "-" to skip)
+func payloadMap(data interface{}) (map[string]interface{}, bool) {
+	if m, ok := data.(map[string]interface{}); ok {
+		payload := make(map[string]interface{}, len(m))
+		for key, v := range m {
+			payload[key] = v
+		}
+		return payload, true
+	}
+	rv := reflect.ValueOf(data)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+	t := rv.Type()
+	payload := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("db")
+		if field.PkgPath != "" || key == "-" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		payload[key] = rv.Field(i).Interface()
+	}
+	return payload, true
+}
+
+// setField - setting struct field by its type, false if type isn't supported
+func setField(field reflect.Value, v interface{}) bool {
+	switch field.Type().String() {
+	case "int64":
+		field.SetInt(getInt64(v))
+	case "float64":
+		field.SetFloat(getFloat64(v))
+	case "string":
+		field.SetString(fmt.Sprintf("%v", v))
+	case "bool":
+		field.SetBool(getBool(v))
+	case "time.Time":
+		field.Set(reflect.ValueOf(getTime(v)))
+	case "[]string", "[]int64", "[]float64", "[]bool":
+		rv := reflect.ValueOf(v)
+		if rv.Type() != field.Type() {
+			return false
+		}
+		field.Set(rv)
+	default:
+		return false
+	}
+	return true
+}
+
+// timeLayouts - layouts of time strings returned by drivers (MySQL without parseTime, SQLite)
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
 func getTime(v interface{}) time.Time {
 	switch v.(type) {
 	case int64:
-		str := strconv.FormatInt(v.(int64), 10)
-		t, _ := time.Parse("U", str)
-		return t
+		return time.Unix(v.(int64), 0)
+	case []byte:
+		return getTime(string(v.([]byte)))
 	case string:
-		t, _ := time.Parse(time.RFC3339, v.(string))
-		return t
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v.(string)); err == nil {
+				return t
+			}
+		}
+		return time.Time{}
 	case time.Time:
 		return v.(time.Time)
 	}
@@ -97,8 +162,17 @@ func getFloat64(v interface{}) float64 {
 	return 0
 }
 func getBool(v interface{}) bool {
-	if b, ok := v.(bool); ok {
+	switch b := v.(type) {
+	case bool:
 		return b
+	case int64:
+		// MySQL BOOL is TINYINT(1)
+		return b != 0
+	case []byte:
+		return getBool(string(b))
+	case string:
+		parsed, _ := strconv.ParseBool(b)
+		return parsed
 	}
 	return false
 }
@@ -118,21 +192,35 @@ func parseParams(params interface{}) (m QueryModificator) {
 		if p["limit"] != nil {
 			m.limit = p["limit"].(int)
 		}
+		if p["settings"] != nil {
+			m.settings = p["settings"].(Settings)
+		}
 		if p["orderBy"] != nil {
-			var orderParams builders.OrderParam
-			var orderParamsArr []builders.OrderParam
-
-			orderParams.OrderBy = p["orderBy"].(string)
-
-			if p["order"] == "asc" {
-				orderParams.Asc = true
-			} else {
-				orderParams.Desc = true
+			var nulls string
+			if n, ok := p["nulls"].(string); ok && (n == "first" || n == "last") {
+				nulls = strings.ToUpper(n)
 			}
-
-			orderParamsArr = append(orderParamsArr, orderParams)
-			m.orderBy = orderParamsArr
+			m.orderBy = parseOrder(p["orderBy"], p["order"] != "asc", nulls)
 		}
 	}
 	return
 }
+
+func inArray(needle string, haystack []string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName - cutting operator from query key: "age>=" -> "age"
+func fieldName(key string) string {
+	for i, c := range key {
+		if !(c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return key[:i]
+		}
+	}
+	return key
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+)
+
+type nullItem struct {
+	ID     int64   `db:"id"`
+	Name   string  `db:"name"`
+	Active bool    `db:"active"`
+	Note   *string `db:"note"`
+}
+
+func TestPopulateStructByMapNull(t *testing.T) {
+	rv := reflect.New(reflect.TypeOf(nullItem{}))
+	first := populateStructByMap(rv, map[string]interface{}{"id": int64(1), "name": "a", "active": true, "note": "n"}).(nullItem)
+	if first.Note == nil || *first.Note != "n" || !first.Active {
+		t.Fatalf("first row: %+v", first)
+	}
+	// same value is populated again, NULL columns must not keep previous values
+	second := populateStructByMap(rv, map[string]interface{}{"id": int64(2), "name": nil, "active": nil, "note": nil}).(nullItem)
+	if second != (nullItem{ID: 2}) {
+		t.Fatalf("second row: %+v", second)
+	}
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import "context"
+
+// Lifecycle events of AddHook
+const (
+	// BeforeCreate - called with payload of Create/Upsert, returned value is inserted
+	BeforeCreate = "beforeCreate"
+	// AfterCreate - called with created item, returned value is result of Create/Upsert
+	AfterCreate = "afterCreate"
+	// BeforeUpdate - called with payload of Update, has to return payload map
+	BeforeUpdate = "beforeUpdate"
+	// AfterUpdate - called with updated item, returned value is result of Update
+	AfterUpdate = "afterUpdate"
+	// BeforeDelete - called with query of Delete/DeleteByID
+	BeforeDelete = "beforeDelete"
+	// AfterDelete - called with query of Delete/DeleteByID after rows are deleted
+	AfterDelete = "afterDelete"
+)
+
+/*
+HookFunc - lifecycle hook: called with repository context (WithContext) and payload or result
+of operation. Returned value replaces it, error aborts operation (before hooks)
+or is returned after it is done (after hooks)
+*/
+type HookFunc func(ctx context.Context, data interface{}) (interface{}, error)
+
+/*
+AddHook - registering hook called around Create, Upsert, Update, Delete and DeleteByID
+(defaulting, derived fields, events). Hooks of event are called in order of registration.
+Bulk operations (CreateMany, UpdateMany, DeleteInBatches...) don't call hooks
+*/
+func (ds *Postgres) AddHook(event string, fn HookFunc) {
+	if ds.hooks == nil {
+		ds.hooks = make(map[string][]HookFunc)
+	}
+	ds.hooks[event] = append(ds.hooks[event], fn)
+}
+
+// runHooks - data passed through hooks of event
+func (ds *Postgres) runHooks(event string, data interface{}) (interface{}, error) {
+	for _, fn := range ds.hooks[event] {
+		var err error
+		if data, err = fn(ds.Context(), data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+/*
+Index - index declaration. Expression is used instead of Columns for expression indexes (lower(email)),
+Where makes index partial, Concurrently creates it without blocking writes.
+Indexes are declared with model tags (parts separated by ";", fields with same name make composite index):
+
+	Email string `db:"email" index:"users_email_key;unique;concurrently;expr=lower(email);where=deleted_at IS NULL"`
+
+or with DeclareIndex
+*/
+type Index struct {
+	Name         string
+	Columns      []string
+	Expression   string
+	Unique       bool
+	Where        string
+	Concurrently bool
+}
+
+/*
+IndexDrift - declared index missing in database or differing from declaration
+*/
+type IndexDrift struct {
+	Index   Index
+	Problem string
+}
+
+// SQL - CREATE INDEX statement for table (Postgres)
+func (i Index) SQL(table string) string {
+	SQL, _, _ := builders.NewPostgres().CreateIndex(table, i.builderIndex()).Build()
+	return SQL
+}
+
+func (i Index) builderIndex() builders.Index {
+	return builders.Index{
+		Name:         i.Name,
+		Columns:      i.Columns,
+		Expression:   i.Expression,
+		Unique:       i.Unique,
+		Where:        i.Where,
+		Concurrently: i.Concurrently,
+	}
+}
+
+// getIndexesByModel - indexes declared with `index` tags
+func getIndexesByModel(model interface{}) (indexes []Index) {
+	if model == nil {
+		return
+	}
+	byName := make(map[string]*Index)
+	var names []string
+	st := reflect.ValueOf(model).Elem().Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		tag := field.Tag.Get("index")
+		if tag == "" {
+			continue
+		}
+		column := field.Name
+		if field.Tag.Get("db") != "" {
+			column = field.Tag.Get("db")
+		}
+		parts := strings.Split(tag, ";")
+		index, ok := byName[parts[0]]
+		if !ok {
+			index = &Index{Name: parts[0]}
+			byName[parts[0]] = index
+			names = append(names, parts[0])
+		}
+		index.Columns = append(index.Columns, column)
+		for _, p := range parts[1:] {
+			switch {
+			case p == "unique":
+				index.Unique = true
+			case p == "concurrently":
+				index.Concurrently = true
+			case strings.HasPrefix(p, "expr="):
+				index.Expression = strings.TrimPrefix(p, "expr=")
+			case strings.HasPrefix(p, "where="):
+				index.Where = strings.TrimPrefix(p, "where=")
+			}
+		}
+	}
+	for _, name := range names {
+		indexes = append(indexes, *byName[name])
+	}
+	return
+}
+
+/*
+DeclareIndex - declaring index in addition to model tags
+*/
+func (ds *Postgres) DeclareIndex(index Index) {
+	ds.indexes = append(ds.indexes, index)
+}
+
+/*
+Indexes - declared indexes of repository
+*/
+func (ds *Postgres) Indexes() []Index {
+	return append(getIndexesByModel(ds.model), ds.indexes...)
+}
+
+/*
+MigrateIndexes - creating declared indexes that don't exist
+*/
+func (ds *Postgres) MigrateIndexes() error {
+	return ds.EnsureIndexes(nil)
+}
+
+/*
+EnsureIndexes - creating indexes declared with `index` tags of model that don't exist
+(IF NOT EXISTS). Nil model - indexes of repository model and DeclareIndex
+*/
+func (ds *Postgres) EnsureIndexes(model interface{}) error {
+	indexes := ds.Indexes()
+	if model != nil {
+		indexes = getIndexesByModel(model)
+	}
+	for _, index := range indexes {
+		SQL, args, err := ds.build("EnsureIndexes", ds.adapter.Builder().CreateIndex(ds.source, index.builderIndex()))
+		if err != nil {
+			return err
+		}
+		if _, err := ds.adapter.Exec(SQL, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+CheckIndexes - comparing declared indexes with database (pg_indexes):
+missing indexes, uniqueness and partial predicate presence
+*/
+func (ds *Postgres) CheckIndexes() ([]IndexDrift, error) {
+	rows, err := ds.adapter.Query("SELECT indexname, indexdef FROM pg_indexes WHERE tablename = $1", ds.source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	existing := make(map[string]string)
+	for rows.Next() {
+		var name, def string
+		if err := rows.Scan(&name, &def); err != nil {
+			return nil, err
+		}
+		existing[name] = def
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var drift []IndexDrift
+	for _, index := range ds.Indexes() {
+		def, ok := existing[index.Name]
+		switch {
+		case !ok:
+			drift = append(drift, IndexDrift{index, "missing"})
+		case index.Unique != strings.HasPrefix(def, "CREATE UNIQUE"):
+			drift = append(drift, IndexDrift{index, "uniqueness differs"})
+		case (index.Where != "") != strings.Contains(def, " WHERE "):
+			drift = append(drift, IndexDrift{index, "partial predicate differs"})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Index.Name < drift[j].Index.Name })
+	return drift, nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	lib "github.com/niklucky/go-lib"
+	"github.com/niklucky/vodka/builders"
+)
+
+/*
+JoinRepository - joining source of other repository with all columns of its model
+(discovered columns in dynamic mode). Joined columns of every row are set into model field
+tagged `join:"<source>"` (struct, pointer to struct or map), pointer stays nil if LEFT join
+found nothing. Map results (dynamic mode) get them as "<source>.<column>" keys
+*/
+func (ds *Postgres) JoinRepository(repo *Postgres, key, targetKey, joinType string) {
+	var fields []string
+	if repo.model != nil {
+		fields = lib.GetStructTags(reflect.ValueOf(repo.model).Elem(), "db", true)
+	} else {
+		for column := range repo.columns {
+			fields = append(fields, column)
+		}
+		sort.Strings(fields)
+	}
+	ds.Join(repo.source, key, targetKey, joinType, fields)
+}
+
+// joins - joined sources in stable order (aliases of builder depend on it)
+func (ds *Postgres) joins() []builders.Join {
+	keys := make([]string, 0, len(ds.joinedRepositories))
+	for key := range ds.joinedRepositories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	joins := make([]builders.Join, len(keys))
+	for i, key := range keys {
+		joins[i] = ds.joinedRepositories[key]
+	}
+	return joins
+}
+
+/*
+splitJoined - moving joined columns (alias__column, see builders.JoinedColumn) out of row
+into maps by join alias
+*/
+func (ds *Postgres) splitJoined(row map[string]interface{}) map[string]map[string]interface{} {
+	if len(ds.joinedRepositories) == 0 {
+		return nil
+	}
+	joined := make(map[string]map[string]interface{})
+	for column, v := range row {
+		i := strings.Index(column, "__")
+		if i == -1 {
+			continue
+		}
+		alias := column[:i]
+		if _, ok := ds.joinedRepositories[alias]; !ok {
+			continue
+		}
+		if joined[alias] == nil {
+			joined[alias] = make(map[string]interface{})
+		}
+		joined[alias][column[i+2:]] = v
+		delete(row, column)
+	}
+	return joined
+}
+
+// flattenJoined - joined columns as "alias.column" keys of map result
+func flattenJoined(row map[string]interface{}, joined map[string]map[string]interface{}) {
+	for alias, columns := range joined {
+		for column, v := range columns {
+			row[alias+"."+column] = v
+		}
+	}
+}
+
+// setJoined - item (model struct) with joined rows set into fields tagged `join:"alias"`
+func setJoined(item interface{}, joined map[string]map[string]interface{}) interface{} {
+	if len(joined) == 0 {
+		return item
+	}
+	v := reflect.New(reflect.TypeOf(item)).Elem()
+	v.Set(reflect.ValueOf(item))
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		columns, ok := joined[t.Field(i).Tag.Get("join")]
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		switch {
+		case reflect.TypeOf(columns).ConvertibleTo(field.Type()):
+			field.Set(reflect.ValueOf(columns).Convert(field.Type()))
+		case field.Kind() == reflect.Struct:
+			field.Set(reflect.ValueOf(populateStructByMap(reflect.New(field.Type()), columns)))
+		case field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct && !allNil(columns):
+			ptr := reflect.New(field.Type().Elem())
+			ptr.Elem().Set(reflect.ValueOf(populateStructByMap(reflect.New(field.Type().Elem()), columns)))
+			field.Set(ptr)
+		}
+	}
+	return v.Interface()
+}
+
+// allNil - LEFT join found no row
+func allNil(columns map[string]interface{}) bool {
+	for _, v := range columns {
+		if v != nil {
+			return false
+		}
+	}
+	return true
+}
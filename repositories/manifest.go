@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/niklucky/vodka/adapters"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var models = make(map[string]interface{})
+
+/*
+Manifest - declarative description of repositories.
+Could be loaded from YAML or JSON file with LoadManifest
+*/
+type Manifest struct {
+	Repositories []Definition `json:"repositories" yaml:"repositories"`
+}
+
+/*
+Definition - repository definition in manifest
+*/
+type Definition struct {
+	Name      string              `json:"name" yaml:"name"`
+	Source    string              `json:"source" yaml:"source"`
+	Model     string              `json:"model" yaml:"model"`
+	Relations []Relation          `json:"relations" yaml:"relations"`
+	Scopes    map[string]QueryMap `json:"scopes" yaml:"scopes"`
+	Cache     CachePolicy         `json:"cache" yaml:"cache"`
+	Filters   []string            `json:"filters" yaml:"filters"`
+	Sorts     []string            `json:"sorts" yaml:"sorts"`
+	ReadOnly  bool                `json:"readOnly" yaml:"readOnly"`
+	Sensitive []string            `json:"sensitive" yaml:"sensitive"`
+	CostGuard *CostGuard          `json:"costGuard" yaml:"costGuard"`
+	Profiles  map[string][]string `json:"profiles" yaml:"profiles"`
+}
+
+/*
+Relation - source joined to repository (see Postgres.Join)
+*/
+type Relation struct {
+	Source    string   `json:"source" yaml:"source"`
+	Key       string   `json:"key" yaml:"key"`
+	TargetKey string   `json:"targetKey" yaml:"targetKey"`
+	Type      string   `json:"type" yaml:"type"`
+	Fields    []string `json:"fields" yaml:"fields"`
+}
+
+/*
+CachePolicy - caching params of repository (see Postgres.SetCache).
+TTL is a duration string (e.g. "5m"), Size is max number of cached items,
+Channel is NOTIFY channel for invalidations between instances
+*/
+type CachePolicy struct {
+	TTL     string `json:"ttl" yaml:"ttl"`
+	Size    int    `json:"size" yaml:"size"`
+	Channel string `json:"channel" yaml:"channel"`
+}
+
+// RegisterModel - registering model by name so it could be referenced in manifest
+func RegisterModel(name string, model interface{}) {
+	models[name] = model
+}
+
+/*
+LoadManifest - reading manifest file and building repositories.
+Files with .yml/.yaml extension are parsed as YAML, everything else as JSON
+*/
+func LoadManifest(adapter adapters.Adapter, fileName string) (map[string]*Postgres, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &m)
+	default:
+		err = json.Unmarshal(data, &m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s: %v", fileName, err)
+	}
+	return m.Build(adapter)
+}
+
+/*
+Build - building repositories described in manifest. Result is keyed by repository name
+(source is used if name is not set)
+*/
+func (m Manifest) Build(adapter adapters.Adapter) (map[string]*Postgres, error) {
+	repos := make(map[string]*Postgres)
+	for _, d := range m.Repositories {
+		if d.Source == "" {
+			return nil, fmt.Errorf("manifest: repository %s has no source", d.Name)
+		}
+		name := d.Name
+		if name == "" {
+			name = d.Source
+		}
+		if _, ok := repos[name]; ok {
+			return nil, fmt.Errorf("manifest: repository %s is defined twice", name)
+		}
+		var repo *Postgres
+		if d.Model == "" {
+			// no model - working in dynamic mode
+			var err error
+			if repo, err = NewDynamic(adapter, d.Source); err != nil {
+				return nil, err
+			}
+		} else {
+			model, ok := models[d.Model]
+			if !ok {
+				return nil, fmt.Errorf("manifest: model %s of repository %s is not registered", d.Model, name)
+			}
+			repo = NewPostgres(adapter, d.Source, model)
+		}
+		for _, r := range d.Relations {
+			repo.Join(r.Source, r.Key, r.TargetKey, r.Type, r.Fields)
+		}
+		repo.scopes = d.Scopes
+		repo.filters = d.Filters
+		repo.sorts = d.Sorts
+		if d.Cache != (CachePolicy{}) {
+			if err := repo.SetCache(d.Cache); err != nil {
+				return nil, fmt.Errorf("manifest: repository %s: %v", name, err)
+			}
+		}
+		repo.readOnly = d.ReadOnly
+		repo.SetSensitive(d.Sensitive...)
+		repo.costGuard = d.CostGuard
+		for name, fields := range d.Profiles {
+			repo.SetProfile(name, fields...)
+		}
+		repos[name] = repo
+	}
+	return repos, nil
+}
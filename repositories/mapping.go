@@ -0,0 +1,57 @@
+package repositories
+
+import "sync"
+
+/*
+ConcurrentMapper - mapper opting into concurrent mapping of collections.
+Items of Find results are mapped with Item by pool of Concurrency() workers,
+order of items is preserved and Collection is not called
+*/
+type ConcurrentMapper interface {
+	Mapper
+	Concurrency() int
+}
+
+// mapConcurrently - mapping items with bounded pool of workers, first error is returned
+func mapConcurrently(data []interface{}, workers int, fn func(interface{}) (interface{}, error)) ([]interface{}, error) {
+	result := make([]interface{}, len(data))
+	if workers > len(data) {
+		workers = len(data)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	done := make(chan struct{})
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item, err := fn(data[i])
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						close(done)
+					})
+					continue
+				}
+				result[i] = item
+			}
+		}()
+	}
+feed:
+	for i := range data {
+		select {
+		case indexes <- i:
+		case <-done:
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
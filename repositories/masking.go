@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+// sensitiveTags - model tags marking fields whose values are masked in debug SQL
+var sensitiveTags = []string{"pii", "password"}
+
+// getSensitiveByModel - columns of fields tagged `pii:"true"` or `password:"true"`
+func getSensitiveByModel(model interface{}) (columns []string) {
+	if model == nil {
+		return
+	}
+	st := reflect.ValueOf(model).Elem().Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		for _, tag := range sensitiveTags {
+			if field.Tag.Get(tag) == "" || field.Tag.Get(tag) == "false" {
+				continue
+			}
+			name := field.Name
+			if field.Tag.Get("db") != "" {
+				name = field.Tag.Get("db")
+			}
+			columns = append(columns, name)
+			break
+		}
+	}
+	return
+}
+
+/*
+SetSensitive - columns whose values are masked in debug SQL output
+(in addition to model fields tagged `pii:"true"` or `password:"true"`)
+*/
+func (ds *Postgres) SetSensitive(columns ...string) {
+	ds.sensitive = append(ds.sensitive, columns...)
+}
+
+// debugSQL - SQL and arguments for debug output with sensitive values masked.
+// Builder is built again, so it has to be called after SQL is built
+func (ds *Postgres) debugSQL(b builders.Builder, SQL string, args []interface{}) string {
+	if len(ds.sensitive) > 0 {
+		SQL, args, _ = b.Mask(ds.sensitive).Build()
+	}
+	return fmt.Sprint(SQL, " ", args)
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import "fmt"
+
+/*
+QuerySpec - single Find of FindMulti. Key identifies result
+*/
+type QuerySpec struct {
+	Key    string
+	Query  QueryMap
+	Params ParamsMap
+}
+
+/*
+FindMulti - running several Find concurrently (up to defaultMultiWorkers queries at once,
+so pool isn't exhausted) and returning results keyed by QuerySpec.Key.
+First error fails whole call, specs that aren't started yet are skipped
+*/
+func (ds *Postgres) FindMulti(specs []QuerySpec) (map[string]interface{}, error) {
+	data := make([]interface{}, len(specs))
+	keys := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		if keys[spec.Key] {
+			return nil, fmt.Errorf("find multi: key %s is used twice", spec.Key)
+		}
+		keys[spec.Key] = true
+		data[i] = spec
+	}
+	items, err := mapConcurrently(data, defaultMultiWorkers, func(item interface{}) (interface{}, error) {
+		spec := item.(QuerySpec)
+		if spec.Params == nil {
+			spec.Params = ParamsMap{}
+		}
+		return ds.Find(spec.Query, spec.Params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]interface{}, len(specs))
+	for i, spec := range specs {
+		results[spec.Key] = items[i]
+	}
+	return results, nil
+}
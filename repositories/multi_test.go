@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+type multiItem struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestFindMultiConcurrent(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		name := fmt.Sprint(args[0])
+		rows := make([][]driver.Value, 2000)
+		for i := range rows {
+			rows[i] = []driver.Value{int64(i), name}
+		}
+		return []string{"id", "name"}, rows, nil
+	})
+	repo := NewPostgres(a, "items", &multiItem{})
+	specs := []QuerySpec{
+		{Key: "a", Query: QueryMap{"name": "a"}},
+		{Key: "b", Query: QueryMap{"name": "b"}},
+	}
+	for n := 0; n < 5; n++ {
+		results, err := repo.FindMulti(specs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for key, result := range results {
+			items := result.([]interface{})
+			if len(items) != 2000 {
+				t.Fatalf("%s: %d items, want 2000", key, len(items))
+			}
+			for i, item := range items {
+				if got := item.(multiItem); got.Name != key || got.ID != int64(i) {
+					t.Fatalf("%s: item %d is %+v", key, i, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import "github.com/niklucky/vodka/builders"
+
+/*
+Op - comparison operators of QueryMap value, all set ones have to match:
+
+	QueryMap{"age": Op{Gte: 18, Lt: 65}, "status": Op{In: []string{"new", "paid"}}, "name": Op{ILike: "%ann%"}}
+
+Values are bound as parameters by builder. Empty Op doesn't filter
+*/
+type Op struct {
+	Eq      interface{}
+	Ne      interface{}
+	Gt      interface{}
+	Gte     interface{}
+	Lt      interface{}
+	Lte     interface{}
+	In      interface{} // slice of values, single value is a list of one
+	NotIn   interface{} // slice of values, single value is a list of one
+	Like    string
+	ILike   string
+	IsNull  bool
+	NotNull bool
+}
+
+// Predicate - condition of column with every set operator (see builders.Predicate)
+func (op Op) Predicate(column string) builders.Condition {
+	var items []interface{}
+	add := func(o builders.Operator) {
+		items = append(items, map[string]interface{}{column: o})
+	}
+	values := []struct {
+		sign  string
+		value interface{}
+	}{{"=", op.Eq}, {"<>", op.Ne}, {">", op.Gt}, {">=", op.Gte}, {"<", op.Lt}, {"<=", op.Lte}}
+	for _, v := range values {
+		if v.value != nil {
+			add(builders.Operator{Sign: v.sign, Value: v.value})
+		}
+	}
+	if op.In != nil {
+		add(builders.In(op.In))
+	}
+	if op.NotIn != nil {
+		add(builders.NotIn(op.NotIn))
+	}
+	if op.Like != "" {
+		add(builders.Like(op.Like))
+	}
+	if op.ILike != "" {
+		add(builders.ILike(op.ILike))
+	}
+	if op.IsNull {
+		add(builders.IsNull())
+	}
+	if op.NotNull {
+		add(builders.IsNotNull())
+	}
+	return builders.And(items...)
+}
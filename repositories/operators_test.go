@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+func TestOpPredicate(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Op
+		sql  string
+		args []interface{}
+	}{
+		{"range", Op{Gte: 18, Lt: 65}, "SELECT t.id FROM  t as t WHERE (t.age >= $1 AND t.age < $2)", []interface{}{18, 65}},
+		{"in slice", Op{In: []int{1, 2}}, "SELECT t.id FROM  t as t WHERE t.age IN ($1,$2)", []interface{}{1, 2}},
+		{"in scalar", Op{In: 5}, "SELECT t.id FROM  t as t WHERE t.age IN ($1)", []interface{}{5}},
+		{"not in string", Op{NotIn: "x"}, "SELECT t.id FROM  t as t WHERE t.age NOT IN ($1)", []interface{}{"x"}},
+		{"empty", Op{}, "SELECT t.id FROM  t as t", []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SQL, args, err := builders.NewPostgres().Select([]string{"id"}).From("t").Where(map[string]interface{}{"age": tt.op}).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if SQL != tt.sql || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("got %q %#v, want %q %#v", SQL, args, tt.sql, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+/*
+parseOrder - ORDER BY of Find params["orderBy"]:
+
+	"name"                                            - direction from params["order"] (desc unless "asc")
+	"name asc, -created_at"                           - list in string, "-" is descending
+	[]string{"status", "created_at desc nulls last"}  - list
+	map[string]interface{}{"column": "name", "direction": "asc", "nulls": "last"}
+	[]interface{}{...}                                - mix of above (decoded JSON)
+	builders.OrderParam, []builders.OrderParam
+
+direction is "asc" or "desc", nulls is "first" or "last". Columns of strings and maps
+come from request, so only plain identifiers ("name", "t.name") are accepted, others are skipped.
+Expressions could be ordered by only with builders.OrderParam passed in code
+*/
+func parseOrder(value interface{}, desc bool, nulls string) (order []builders.OrderParam) {
+	switch v := value.(type) {
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if o, ok := parseOrderString(item, desc, nulls); ok {
+				order = append(order, o)
+			}
+		}
+	case []string:
+		for _, item := range v {
+			order = append(order, parseOrder(item, desc, nulls)...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			order = append(order, parseOrder(item, desc, nulls)...)
+		}
+	case map[string]interface{}:
+		column, _ := v["column"].(string)
+		column = strings.TrimSpace(column)
+		if !orderColumn.MatchString(column) {
+			return nil
+		}
+		o := orderParam(column, desc)
+		if direction, ok := v["direction"].(string); ok {
+			o = orderParam(o.OrderBy, strings.EqualFold(direction, "desc"))
+		}
+		o.Nulls = nulls
+		if n, ok := v["nulls"].(string); ok {
+			o.Nulls = strings.ToUpper(n)
+		}
+		order = append(order, o)
+	case ParamsMap:
+		return parseOrder(map[string]interface{}(v), desc, nulls)
+	case []map[string]interface{}:
+		for _, item := range v {
+			order = append(order, parseOrder(item, desc, nulls)...)
+		}
+	case builders.OrderParam:
+		order = append(order, v)
+	case []builders.OrderParam:
+		order = append(order, v...)
+	}
+	return
+}
+
+// orderColumn - column of order params, plain or qualified identifier
+var orderColumn = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// parseOrderString - "column [asc|desc] [nulls first|last]" or "-column"
+func parseOrderString(s string, desc bool, nulls string) (builders.OrderParam, bool) {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return builders.OrderParam{}, false
+	}
+	column := words[0]
+	if strings.HasPrefix(column, "-") {
+		column, desc = column[1:], true
+	} else if strings.HasPrefix(column, "+") {
+		column, desc = column[1:], false
+	}
+	rest := words[1:]
+	if len(rest) > 0 {
+		switch strings.ToLower(rest[0]) {
+		case "asc":
+			desc, rest = false, rest[1:]
+		case "desc":
+			desc, rest = true, rest[1:]
+		}
+	}
+	if len(rest) == 2 && strings.EqualFold(rest[0], "nulls") {
+		nulls, rest = strings.ToUpper(rest[1]), nil
+	}
+	if !orderColumn.MatchString(column) || len(rest) > 0 {
+		return builders.OrderParam{}, false
+	}
+	o := orderParam(column, desc)
+	o.Nulls = nulls
+	return o, true
+}
+
+func orderParam(column string, desc bool) builders.OrderParam {
+	return builders.OrderParam{OrderBy: column, Asc: !desc, Desc: desc}
+}
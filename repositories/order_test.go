@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []builders.OrderParam
+	}{
+		{"string", "name asc, -created_at nulls last", []builders.OrderParam{
+			{OrderBy: "name", Asc: true},
+			{OrderBy: "created_at", Desc: true, Nulls: "LAST"},
+		}},
+		{"qualified", "users.name", []builders.OrderParam{{OrderBy: "users.name", Desc: true}}},
+		{"map", map[string]interface{}{"column": "name", "direction": "asc"}, []builders.OrderParam{{OrderBy: "name", Asc: true}}},
+		{"expression", "(SELECT/**/pg_sleep(10))", nil},
+		{"function", "lower(name)", nil},
+		{"quoted", `"name"`, nil},
+		{"expression in list", []string{"name", "1;DROP TABLE users"}, []builders.OrderParam{{OrderBy: "name", Desc: true}}},
+		{"expression in map", map[string]interface{}{"column": "(SELECT 1)"}, nil},
+		{"order param", builders.OrderParam{OrderBy: "lower(name)", Asc: true}, []builders.OrderParam{{OrderBy: "lower(name)", Asc: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOrder(tt.value, true, ""); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOrder(%#v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindOrderByExpression(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id"}, nil, nil
+	})
+	repo := NewPostgres(a, "items", nil)
+	if _, err := repo.Find(QueryMap{}, ParamsMap{"orderBy": "(SELECT/**/pg_sleep(10))"}); err != nil {
+		t.Fatal(err)
+	}
+	if log := a.statements(); strings.Contains(log[0], "pg_sleep") {
+		t.Fatalf("orderBy expression is rendered: %q", log[0])
+	}
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/*
+PivotSpec - pivot params: values of Row field become rows,
+values of Column field become columns and Value field is summed into cells
+*/
+type PivotSpec struct {
+	Row    string
+	Column string
+	Value  string
+}
+
+/*
+PivotTable - wide result of Pivot. Values of every row are in order of Columns
+*/
+type PivotTable struct {
+	Columns []interface{} `json:"columns"`
+	Rows    []PivotRow    `json:"rows"`
+}
+
+/*
+PivotRow - single row of PivotTable. Missing cells are 0
+*/
+type PivotRow struct {
+	Key    interface{} `json:"key"`
+	Values []float64   `json:"values"`
+}
+
+/*
+Pivot - turning grouped rows (e.g. Find result or QueryMapRows) into wide table.
+Items could be maps or structs (fields are matched by `db` tag or name).
+Rows and columns keep order of first appearance
+*/
+func Pivot(items interface{}, spec PivotSpec) (table PivotTable, err error) {
+	rv := reflect.ValueOf(items)
+	if rv.Kind() != reflect.Slice {
+		return table, fmt.Errorf("pivot: items must be a slice, got %T", items)
+	}
+	rowIndex := make(map[interface{}]int)
+	colIndex := make(map[interface{}]int)
+	var cells [][]float64
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		row, ok := fieldValue(item, spec.Row)
+		if !ok {
+			return table, fmt.Errorf("pivot: item %d has no field %s", i, spec.Row)
+		}
+		col, ok := fieldValue(item, spec.Column)
+		if !ok {
+			return table, fmt.Errorf("pivot: item %d has no field %s", i, spec.Column)
+		}
+		value, _ := fieldValue(item, spec.Value)
+
+		r, ok := rowIndex[row]
+		if !ok {
+			r = len(table.Rows)
+			rowIndex[row] = r
+			table.Rows = append(table.Rows, PivotRow{Key: row})
+			cells = append(cells, make([]float64, len(table.Columns)))
+		}
+		c, ok := colIndex[col]
+		if !ok {
+			c = len(table.Columns)
+			colIndex[col] = c
+			table.Columns = append(table.Columns, col)
+			for n := range cells {
+				cells[n] = append(cells[n], 0)
+			}
+		}
+		cells[r][c] += getFloat64(value)
+	}
+	for n := range table.Rows {
+		table.Rows[n].Values = cells[n]
+	}
+	return table, nil
+}
+
+// fieldValue - getting value from map by key or from struct by `db` tag/field name
+func fieldValue(item interface{}, name string) (interface{}, bool) {
+	rv := reflect.ValueOf(item)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil, false
+		}
+		return v.Interface(), true
+	case reflect.Struct:
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Tag.Get("db") == name || t.Field(i).Name == name {
+				return rv.Field(i).Interface(), true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"reflect"
+
+	"github.com/niklucky/vodka"
+	"github.com/niklucky/vodka/adapters"
+)
+
+/*
+Pluck - values of single column of rows matching query scanned into dest,
+a pointer to typed slice (e.g. *[]int64 for ids). Not limited, mapper is not applied
+*/
+func (ds *Postgres) Pluck(dest interface{}, column string, query QueryMap) error {
+	if err := ds.checkAllowed(query, nil); err != nil {
+		return err
+	}
+	builder := ds.adapter.Builder().Select([]string{column}).From(ds.source).Where(query)
+	SQL, args, err := ds.build("Pluck", builder)
+	if err != nil {
+		return err
+	}
+	return adapters.QueryColumn(ds.adapter, dest, SQL, args...)
+}
+
+/*
+MapBy - Find result keyed by keyColumn (db tag, field or map key) of every model.
+Params are the same as in Find (limit, order, scope), later rows win on duplicate keys
+*/
+func (ds *Postgres) MapBy(keyColumn string, query QueryMap, params ParamsMap) (map[interface{}]interface{}, error) {
+	if err := ds.checkAllowed(query, params); err != nil {
+		return nil, err
+	}
+	query, err := ds.applyScope(query, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ds.fetch(query, params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ds.mapCollection(rows)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[interface{}]interface{}, len(rows))
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Slice {
+		return items, nil
+	}
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		key, ok := fieldValue(item, keyColumn)
+		if !ok {
+			return nil, vodka.NewServerError("unknown_key", "Key "+keyColumn+" is not found in "+ds.source+" item")
+		}
+		if b, ok := key.([]byte); ok {
+			key = string(b)
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, vodka.NewServerError("invalid_key", "Key "+keyColumn+" of "+ds.source+" could not be map key")
+		}
+		items[key] = item
+	}
+	return items, nil
+}
@@ -1,11 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/niklucky/vodka/builders"
 
@@ -21,31 +23,163 @@ Postgres - is responsible for storing/fetching data
 */
 type Postgres struct {
 	adapter            adapters.Adapter
-	key                string
+	keys               []string // primary key columns, many for composite key
 	model              interface{}
 	source             string
 	mapper             Mapper
 	debug              bool
 	joinedRepositories map[string]builders.Join
+	scopes             map[string]QueryMap
+	filters            []string
+	sorts              []string
+	cachePolicy        CachePolicy
+	columns            map[string]string // discovered columns in dynamic mode
+	readOnly           bool
+	sensitive          []string // columns masked in debug output
+	envelope           bool
+	settings           Settings // planner settings applied to reads
+	ctx                context.Context
+	indexes            []Index // indexes declared in addition to model tags
+	costGuard          *CostGuard
+	profiles           map[string][]string    // serialization profiles: name -> fields
+	profile            string                 // profile of results (WithProfile)
+	modifiedColumn     string                 // column with modification time (LastModified)
+	flights            *flightGroup           // coalescing of identical concurrent reads
+	cache              *entityCache           // FindByID cache (SetCache)
+	cursorKeys         []string               // order of FindCursor pages (SetCursorKey)
+	autoCreate         []string               // columns set to current time by Create (autoCreateTime tag)
+	autoUpdate         []string               // columns set to current time by Create and Update (autoUpdateTime tag)
+	version            string                 // optimistic locking column (version tag)
+	hooks              map[string][]HookFunc  // lifecycle hooks by event (AddHook)
+	rules              []fieldRule            // validation rules of model fields (tags)
+	relations          map[string]Association // relations loaded with preload param (HasOne, HasMany, BelongsTo)
 }
 
-var defaultParams = make(map[string]interface{})
-
-// getKeyByModel - getting primary key for model to select after create
-func getKeyByModel(model interface{}) (key string) {
+// getKeysByModel - primary key columns of model (fields tagged `key`), many for composite key
+func getKeysByModel(model interface{}) (keys []string) {
+	if model == nil {
+		return
+	}
 	st := reflect.ValueOf(model).Elem().Type()
 	for i := 0; i < st.NumField(); i++ {
 		field := st.Field(i)
 		if field.Tag.Get("key") != "" {
-			key = field.Name
+			key := field.Name
 			if field.Tag.Get("db") != "" {
 				key = field.Tag.Get("db")
 			}
+			keys = append(keys, key)
 		}
 	}
 	return
 }
 
+// keyColumns - primary key columns of repository (`key` tags of model), id by default
+func (ds *Postgres) keyColumns() []string {
+	if len(ds.keys) == 0 {
+		return []string{"id"}
+	}
+	return ds.keys
+}
+
+// keyColumn - primary key column of repository, first one of composite key
+func (ds *Postgres) keyColumn() string {
+	return ds.keyColumns()[0]
+}
+
+/*
+keyQuery - query by primary key: id is value of single key,
+map of key columns or values of key columns in order (composite key)
+*/
+func (ds *Postgres) keyQuery(id interface{}) (QueryMap, error) {
+	columns := ds.keyColumns()
+	q := make(QueryMap, len(columns))
+	switch v := id.(type) {
+	case map[string]interface{}:
+		for _, column := range columns {
+			if _, ok := v[column]; !ok {
+				return nil, vodka.NewBadRequestError("invalid_key", "Key "+column+" of "+ds.source+" is not set")
+			}
+			q[column] = v[column]
+		}
+	case QueryMap:
+		return ds.keyQuery(map[string]interface{}(v))
+	case []interface{}:
+		if len(v) != len(columns) {
+			return nil, vodka.NewBadRequestError("invalid_key", "Key of "+ds.source+" has columns "+strings.Join(columns, ", "))
+		}
+		for i, column := range columns {
+			q[column] = v[i]
+		}
+	default:
+		if len(columns) > 1 {
+			return nil, vodka.NewBadRequestError("invalid_key", "Key of "+ds.source+" has columns "+strings.Join(columns, ", "))
+		}
+		q[columns[0]] = id
+	}
+	return q, nil
+}
+
+// payloadKey - query by primary key values of payload (map or model struct), false if some are not set
+func (ds *Postgres) payloadKey(data interface{}) (QueryMap, bool) {
+	if len(ds.keys) == 0 {
+		return nil, false
+	}
+	q := make(QueryMap, len(ds.keys))
+	for _, column := range ds.keys {
+		v, ok := fieldValue(data, column)
+		if !ok || v == nil || reflect.ValueOf(v).IsZero() {
+			return nil, false
+		}
+		q[column] = v
+	}
+	return q, true
+}
+
+/*
+rowQuery - query matching single row: query itself if it sets every key column,
+otherwise query with key of first matching row added (false if no row matches)
+*/
+func (ds *Postgres) rowQuery(q QueryMap) (QueryMap, bool, error) {
+	columns := ds.keyColumns()
+	keyed := true
+	for _, column := range columns {
+		keyed = keyed && isKeyValue(q[column])
+	}
+	if keyed {
+		return q, true, nil
+	}
+	builder := ds.adapter.Builder().Select(columns).From(ds.source).Where(q).Limit(1, 0)
+	SQL, args, err := ds.build("Update", builder)
+	if err != nil {
+		return nil, false, err
+	}
+	rows, err := adapters.QueryMapRows(ds.adapter, SQL, args...)
+	if err != nil || len(rows) == 0 {
+		return nil, false, err
+	}
+	where := make(QueryMap, len(q)+len(columns))
+	for key, v := range q {
+		where[key] = v
+	}
+	for _, column := range columns {
+		where[column] = rows[0][column]
+	}
+	return where, true, nil
+}
+
+// isKeyValue - value of key column matching single row (not list, operator or condition)
+func isKeyValue(v interface{}) bool {
+	switch v.(type) {
+	case nil, builders.Operator, builders.Predicate, builders.Condition, builders.Builder, builders.Subquery:
+		return false
+	case []byte:
+		return true
+	}
+	kind := reflect.TypeOf(v).Kind()
+	return kind != reflect.Slice && kind != reflect.Array && kind != reflect.Map
+}
+
 func isDebug() (debug bool) {
 	if os.Getenv("DEBUG") == "true" {
 		return true
@@ -56,17 +190,28 @@ func isDebug() (debug bool) {
 /*
 NewPostgres - Postgres repository recorder
 */
-func NewPostgres(adapter adapters.Adapter, source string, model interface{}) Recorder {
+func NewPostgres(adapter adapters.Adapter, source string, model interface{}) *Postgres {
+	created, updated := getTimestampsByModel(model)
 	return &Postgres{
 		adapter:            adapter,
-		key:                getKeyByModel(model),
+		keys:               getKeysByModel(model),
 		source:             source,
 		model:              model,
 		debug:              isDebug(),
+		sensitive:          getSensitiveByModel(model),
 		joinedRepositories: make(map[string]builders.Join),
+		autoCreate:         created,
+		autoUpdate:         updated,
+		version:            getVersionByModel(model),
+		rules:              getRulesByModel(model),
 	}
 }
 
+// Source - source (table) of repository
+func (ds *Postgres) Source() string {
+	return ds.source
+}
+
 // SetMapper - setting mapper to process data.
 // By default will be used base mapper that fills provided Model
 // or just will return interface{} with type map[string]interface{}
@@ -74,6 +219,18 @@ func (ds *Postgres) SetMapper(m Mapper) {
 	ds.mapper = m
 }
 
+// SetReadOnly - rejecting all writes (Create/Update/Delete...) of repository
+func (ds *Postgres) SetReadOnly(readOnly bool) {
+	ds.readOnly = readOnly
+}
+
+func (ds *Postgres) checkWritable() error {
+	if ds.readOnly {
+		return vodka.NewError(vodka.ErrorAccessDeniedCode, "read_only", "Repository "+ds.source+" is read-only")
+	}
+	return nil
+}
+
 // Join - joining source to main.
 /*
 @param joinSource name of source to be joined: JOIN joinSource
@@ -90,29 +247,48 @@ func (ds *Postgres) Join(source, key, targetKey, joinType string, fields []strin
 	}
 }
 
+/*
+JoinOn - joining source with full join params (multiple ON conditions, FULL/CROSS joins)
+*/
+func (ds *Postgres) JoinOn(j builders.Join) error {
+	if err := j.Validate(); err != nil {
+		return err
+	}
+	key := j.Alias
+	if key == "" {
+		key = j.Source
+	}
+	ds.joinedRepositories[key] = j
+	return nil
+}
+
 /*
 Create - save data to Storage with Adapter
 */
 func (ds *Postgres) Create(data interface{}) (interface{}, error) {
-	// Checking for auto generated uuid. If found — generating
-	uuidx := ds.generateUUID()
-	var dataMap map[string]interface{}
-	if len(uuidx) > 0 {
-		dataMap = data.(map[string]interface{})
-		for key, v := range uuidx {
-			dataMap[key] = v
-		}
-		data = dataMap
+	data, err := ds.runHooks(BeforeCreate, data)
+	if err != nil {
+		return nil, err
 	}
-	// Starting to build INSERT query
-	builder := ds.adapter.Builder()
-	builder.Insert(ds.source).Values(data)
-	SQL := builder.Build()
+	item, err := ds.create(data)
+	if err != nil {
+		return item, err
+	}
+	return ds.runHooks(AfterCreate, item)
+}
 
-	if ds.debug {
-		fmt.Println("Create SQL: ", SQL)
+func (ds *Postgres) create(data interface{}) (interface{}, error) {
+	data, _, err := ds.prepareCreate(data)
+	if err != nil {
+		return nil, err
+	}
+	// Starting to build INSERT query
+	builder := ds.adapter.Builder().Insert(ds.source).Values(ds.insertRow(data))
+	SQL, args, err := ds.build("Create", builder)
+	if err != nil {
+		return nil, err
 	}
-	result, err := ds.adapter.Exec(SQL)
+	result, err := ds.adapter.Exec(SQL, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -120,20 +296,80 @@ func (ds *Postgres) Create(data interface{}) (interface{}, error) {
 	if id, err := result.LastInsertId(); err == nil {
 		return ds.FindByID(id)
 	}
-	// We have primary key
-	if ds.key != "" && dataMap[ds.key] != nil {
-		items, err := ds.Find(dataMap, defaultParams)
+	// We have primary key, row is read back without filter allowlist (it is for callers' queries)
+	if q, ok := ds.payloadKey(data); ok {
+		rows, err := ds.fetch(q, ParamsMap{"limit": 1})
 		if err != nil {
-			return data, err
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, vodka.NewNotFoundError("not_found", "Item not found")
 		}
-		return items.([]interface{})[0], nil
+		return ds.output(rows[0])
 	}
 	// We have nothing, just returning payload back
 	return data, nil
 }
 
+/*
+insertRow - values of inserted row: struct payload as map without zero key fields
+(serial key is set by database), map payload as is
+*/
+func (ds *Postgres) insertRow(data interface{}) interface{} {
+	if _, ok := data.(map[string]interface{}); ok {
+		return data
+	}
+	row, ok := payloadMap(data)
+	if !ok {
+		return data
+	}
+	for _, column := range ds.keyColumns() {
+		if v, ok := row[column]; ok && (v == nil || reflect.ValueOf(v).IsZero()) {
+			delete(row, column)
+		}
+	}
+	return row
+}
+
+// prepareCreate - validating payload (columns and model rules), generating uuid fields and setting auto timestamps.
+// dataMap is set only if uuid fields were generated (it has no timestamps)
+func (ds *Postgres) prepareCreate(data interface{}) (interface{}, map[string]interface{}, error) {
+	if err := ds.checkWritable(); err != nil {
+		return nil, nil, err
+	}
+	if payload, ok := data.(map[string]interface{}); ok {
+		if err := ds.validateColumns(payload); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := ds.validate(data, true); err != nil {
+		return nil, nil, err
+	}
+	// Checking for auto generated uuid. If found — generating
+	uuidx := ds.generateUUID()
+	var dataMap map[string]interface{}
+	if len(uuidx) > 0 {
+		var ok bool
+		if dataMap, ok = payloadMap(ds.insertRow(data)); !ok {
+			return nil, nil, vodka.NewBadRequestError("invalid_payload", "Payload of "+ds.source+" has to be map or model struct")
+		}
+		for key, v := range uuidx {
+			// uuid passed by caller is kept (e.g. key of Upsert)
+			if current, ok := dataMap[key]; ok && current != nil && !reflect.ValueOf(current).IsZero() {
+				continue
+			}
+			dataMap[key] = v
+		}
+		data = dataMap
+	}
+	return ds.stampCreate(data), dataMap, nil
+}
+
 func (ds *Postgres) generateUUID() (fields map[string]string) {
 	fields = make(map[string]string)
+	if ds.model == nil {
+		return
+	}
 	st := reflect.ValueOf(ds.model).Elem().Type()
 	for i := 0; i < st.NumField(); i++ {
 		field := st.Field(i)
@@ -154,58 +390,141 @@ func (ds *Postgres) generateUUID() (fields map[string]string) {
 Delete - deleteing from storage by query
 */
 func (ds Postgres) Delete(q QueryMap) (interface{}, error) {
+	if err := ds.checkWritable(); err != nil {
+		return nil, err
+	}
+	if _, err := ds.runHooks(BeforeDelete, q); err != nil {
+		return nil, err
+	}
 	builder := ds.adapter.Builder()
-	SQL := builder.Delete().From(ds.source).Where(q).Build()
-	if ds.debug {
-		fmt.Println("Delete SQL: ", SQL)
+	SQL, args, err := ds.build("Delete", builder.Delete().From(ds.source).Where(q))
+	if err != nil {
+		return nil, err
 	}
 
-	rows, err := ds.adapter.Exec(SQL)
+	rows, err := ds.adapter.Exec(SQL, args...)
 	if err != nil {
 		return nil, err
 	}
+	ds.invalidate(q)
+	if _, err := ds.runHooks(AfterDelete, q); err != nil {
+		return rows, err
+	}
 	return rows, nil
 }
 
 /*
-DeleteByID - deleteing from storage by query
+DeleteByID - deleteing from storage by primary key (`key` tag of model, id by default).
+Composite key is map of key columns or slice of their values in order
 */
 func (ds *Postgres) DeleteByID(id interface{}) (interface{}, error) {
+	if err := ds.checkWritable(); err != nil {
+		return nil, err
+	}
 	builder := ds.adapter.Builder()
-	q := make(map[string]interface{})
-	q["id"] = id
-	SQL := builder.Delete().From(ds.source).Where(q).Build()
-	if ds.debug {
-		fmt.Println("DeleteByID SQL: ", SQL)
+	q, err := ds.keyQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ds.runHooks(BeforeDelete, q); err != nil {
+		return nil, err
 	}
-	result, err := ds.adapter.Exec(SQL)
+	SQL, args, err := ds.build("DeleteByID", builder.Delete().From(ds.source).Where(q))
 	if err != nil {
 		return nil, err
 	}
+	result, err := ds.adapter.Exec(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	ds.invalidate(q)
+	if _, err := ds.runHooks(AfterDelete, q); err != nil {
+		return result, err
+	}
 	return result, nil
 }
 
 /*
-Update - updating item in storage by query and payload
+Update - updating item in storage by query and payload.
+Single row is updated (first matching one if query doesn't set primary key),
+returns updated item (RETURNING *) through mapper,
+not found error if nothing matched (see UpdateMany for all matching rows).
+With version column (model tag `version:"true"`) payload has to have version item was read with,
+conflict error (409) is returned if row has other version
 */
 func (ds *Postgres) Update(q QueryMap, payload map[string]interface{}) (interface{}, error) {
-	builder := ds.adapter.Builder()
-	SQL := builder.Update(ds.source).Set(payload).Where(q).Limit(1, 0).Build()
-	if ds.debug {
-		fmt.Println("Update SQL: ", SQL)
+	if err := ds.checkWritable(); err != nil {
+		return nil, err
+	}
+	data, err := ds.runHooks(BeforeUpdate, payload)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, vodka.NewServerError("invalid_hook", BeforeUpdate+" hook of "+ds.source+" has to return payload map")
+	}
+	item, err := ds.update(q, payload)
+	if err != nil {
+		return item, err
+	}
+	return ds.runHooks(AfterUpdate, item)
+}
+
+func (ds *Postgres) update(q QueryMap, payload map[string]interface{}) (interface{}, error) {
+	if err := ds.validateColumns(payload); err != nil {
+		return nil, err
+	}
+	if err := ds.validate(payload, false); err != nil {
+		return nil, err
 	}
-	_, err := ds.adapter.Exec(SQL)
+	payload = ds.stampUpdate(payload)
+	where, payload, err := ds.lockVersion(q, payload)
 	if err != nil {
 		return nil, err
 	}
-	var p ParamsMap
-	// Checking for updated fields
-	for key, v := range payload {
-		if _, ok := q[key]; ok {
-			q[key] = v
+	where, found, err := ds.rowQuery(where)
+	if err != nil {
+		return nil, err
+	}
+	var items []interface{}
+	if found {
+		builder := ds.adapter.Builder().Update(ds.source).Set(payload).Where(where).ReturnID("*")
+		SQL, args, err := ds.build("Update", builder)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := ds.adapter.Query(SQL, args...)
+		if err != nil {
+			return nil, err
+		}
+		items, err = ds.buildResult(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		ds.invalidate(q)
+	}
+	if len(items) == 0 {
+		if ds.version != "" {
+			return nil, vodka.NewConflictError("version_conflict", "Item of "+ds.source+" was changed or deleted")
 		}
+		return nil, vodka.NewNotFoundError("not_found", "Item not found")
 	}
-	return ds.Find(q, p)
+	item, err := ds.mapItem(items[0])
+	if err != nil {
+		return nil, err
+	}
+	return ds.applyProfile(ds.profile, item)
+}
+
+// output - row as returned by reads: through mapper and profile of repository
+func (ds *Postgres) output(row interface{}) (interface{}, error) {
+	item, err := ds.mapItem(row)
+	if err != nil {
+		return nil, err
+	}
+	return ds.applyProfile(ds.profile, item)
 }
 
 /*
@@ -213,85 +532,331 @@ Find - Finding data by query (map key=value) and QueryModificator
 Will return Collection
 */
 func (ds *Postgres) Find(query QueryMap, params ParamsMap) (interface{}, error) {
+	if err := ds.checkAllowed(query, params); err != nil {
+		return nil, err
+	}
+	query, err := ds.applyScope(query, params)
+	if err != nil {
+		return nil, err
+	}
 	rows, err := ds.fetch(query, params)
+	if err == nil {
+		rows, err = ds.preload(rows, params["preload"])
+	}
 	if err != nil {
 		return nil, err
 	}
 	result, err := ds.mapCollection(rows)
 	if d, ok := result.([]interface{}); ok {
 		if len(d) == 0 {
-			return make([]int, 0), err
+			result = make([]int, 0)
+		}
+	}
+	if err == nil {
+		profile := ds.profile
+		if p, ok := params["profile"].(string); ok {
+			profile = p
 		}
+		result, err = ds.applyProfile(profile, result)
+	}
+	estimate := params["estimate"] == true
+	if err == nil && (ds.envelope || params["envelope"] == true || estimate) {
+		mod := parseParams(params)
+		if mod.limit == 0 {
+			mod.limit = defaultLimit
+		}
+		r := Result{Items: result, Count: len(rows), Limit: mod.limit, Skip: mod.skip, Order: mod.orderBy}
+		if estimate {
+			if r.Estimate, err = ds.EstimateCount(query); err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
 	}
 	return result, err
 }
 
+// SetEnvelope - Find returns Result envelope instead of bare collection
+func (ds *Postgres) SetEnvelope(envelope bool) {
+	ds.envelope = envelope
+}
+
 /*
-FindByID - fetching Object by id. interface{} because id could be string or int
+FindByID - fetching Object by primary key (`key` tag of model, id by default).
+interface{} because id could be string or int, map or slice of values for composite key
 */
 func (ds *Postgres) FindByID(id interface{}) (interface{}, error) {
-	q := make(map[string]interface{})
-	if ds.key != "" {
-		q[ds.key] = id
-	} else {
-		q["id"] = id
+	q, err := ds.keyQuery(id)
+	if err != nil {
+		return nil, err
 	}
-	data, err := ds.fetch(q, nil)
+	data, err := ds.fetchByID(id, q)
 	if err != nil {
 		return nil, err
 	}
 	if len(data) > 0 {
-		return ds.mapItem(data[0])
+		item, err := ds.mapItem(data[0])
+		if err != nil {
+			return nil, err
+		}
+		return ds.applyProfile(ds.profile, item)
+	}
+	return nil, vodka.NewNotFoundError("not_found", "Item not found")
+}
+
+/*
+FindOne - first item matching query (params are the same as in Find, limit is 1).
+Returns not found error (vodka.IsNotFound) if there are no rows
+*/
+func (ds *Postgres) FindOne(query QueryMap, params ParamsMap) (interface{}, error) {
+	if err := ds.checkAllowed(query, params); err != nil {
+		return nil, err
+	}
+	p := make(ParamsMap, len(params)+1)
+	for key, v := range params {
+		p[key] = v
+	}
+	p["limit"] = 1
+	query, err := ds.applyScope(query, params)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ds.fetch(query, p)
+	if err == nil {
+		data, err = ds.preload(data, params["preload"])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, vodka.NewNotFoundError("not_found", "Item not found")
+	}
+	item, err := ds.mapItem(data[0])
+	if err != nil {
+		return nil, err
+	}
+	profile := ds.profile
+	if name, ok := params["profile"].(string); ok {
+		profile = name
+	}
+	return ds.applyProfile(profile, item)
+}
+
+/*
+FindBy - single item by value of field (email, slug, external_id...),
+not found error if there is none
+*/
+func (ds *Postgres) FindBy(field string, value interface{}) (interface{}, error) {
+	return ds.FindOne(QueryMap{field: value}, nil)
+}
+
+/*
+DeleteBy - deleting rows by value of field
+*/
+func (ds *Postgres) DeleteBy(field string, value interface{}) (interface{}, error) {
+	return ds.Delete(QueryMap{field: value})
+}
+
+/*
+Count - number of rows matching query (COUNT(*) with the same WHERE and joins as Find)
+*/
+func (ds *Postgres) Count(query QueryMap) (int64, error) {
+	if err := ds.checkAllowed(query, nil); err != nil {
+		return 0, err
+	}
+	qb := ds.adapter.Builder().Select(nil).From(ds.source).Where(query)
+	for _, j := range ds.joins() {
+		qb = qb.Join(j)
+	}
+	SQL, args, err := ds.build("Count", qb.Count("*"))
+	if err != nil {
+		return 0, err
+	}
+	row, err := ds.adapter.QueryRow(SQL, args...)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+/*
+Exists - there is at least one row matching query (SELECT 1 ... LIMIT 1, mapper is not applied)
+*/
+func (ds *Postgres) Exists(query QueryMap) (bool, error) {
+	if err := ds.checkAllowed(query, nil); err != nil {
+		return false, err
+	}
+	qb := ds.adapter.Builder().Select([]string{"1"}).From(ds.source).Where(query).Limit(1, 0)
+	for _, j := range ds.joins() {
+		// joins are only filtering here, their columns are not selected
+		j.Fields = nil
+		qb = qb.Join(j)
+	}
+	SQL, args, err := ds.build("Exists", qb)
+	if err != nil {
+		return false, err
+	}
+	row, err := ds.adapter.QueryRow(SQL, args...)
+	if err != nil {
+		return false, err
+	}
+	var one int
+	switch err := row.Scan(&one); err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// checkAllowed - checking query and order fields against allowlists (if defined)
+func (ds *Postgres) checkAllowed(query QueryMap, params ParamsMap) error {
+	if len(ds.filters) > 0 {
+		for key := range query {
+			if !inArray(fieldName(key), ds.filters) {
+				return vodka.NewBadRequestError("filter_not_allowed", "Filtering by "+fieldName(key)+" is not allowed")
+			}
+		}
+	}
+	if len(ds.sorts) > 0 {
+		for _, o := range parseParams(params).orderBy {
+			if !inArray(o.OrderBy, ds.sorts) {
+				return vodka.NewBadRequestError("sort_not_allowed", "Sorting by "+o.OrderBy+" is not allowed")
+			}
+		}
 	}
-	return nil, vodka.NewError(404, "not_found", "Item not found")
+	return nil
+}
+
+// applyScope - merging named scope (params["scope"]) into query
+func (ds *Postgres) applyScope(query QueryMap, params ParamsMap) (QueryMap, error) {
+	value, ok := params["scope"]
+	if !ok || value == nil {
+		return query, nil
+	}
+	// scopes could be tenant or soft-delete filters, so misspelled one is not ignored
+	name, ok := value.(string)
+	if !ok || ds.scopes[name] == nil {
+		return nil, vodka.NewBadRequestError("unknown_scope", fmt.Sprintf("Scope %v of %s is not declared", value, ds.source))
+	}
+	q := make(QueryMap)
+	for key, v := range query {
+		q[key] = v
+	}
+	for key, v := range ds.scopes[name] {
+		q[key] = v
+	}
+	return q, nil
 }
 
 func (ds *Postgres) fetch(query QueryMap, params interface{}) ([]interface{}, error) {
+	mod := parseParams(params)
+	SQL, args, err := ds.build("Fetch", ds.selectBuilder(query, mod))
+	if err != nil {
+		return nil, err
+	}
+	if ds.flights == nil {
+		return ds.runFetch(query, mod, SQL, args)
+	}
+	key := fmt.Sprint(SQL, args, ds.settings.merge(mod.settings))
+	return ds.flights.do(key, func() ([]interface{}, error) {
+		return ds.runFetch(query, mod, SQL, args)
+	})
+}
+
+// runFetch - checking cost and running built Find query
+func (ds *Postgres) runFetch(query QueryMap, mod QueryModificator, SQL string, args []interface{}) ([]interface{}, error) {
+	if ds.costGuard != nil {
+		if mod.limit == 0 {
+			mod.limit = defaultLimit
+		}
+		limit, err := ds.checkCost(SQL, args, mod.limit)
+		if err != nil {
+			return nil, err
+		}
+		if limit != mod.limit {
+			mod.limit = limit
+			if SQL, args, err = ds.build("Fetch", ds.selectBuilder(query, mod)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if settings := ds.settings.merge(mod.settings); len(settings) > 0 {
+		return ds.queryWithSettings(settings, SQL, args)
+	}
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return nil, err
+	}
+	defer rows.Close()
+	return ds.buildResult(rows)
+}
+
+// selectBuilder - SELECT by query with fields, limit, joins and order from params
+func (ds *Postgres) selectBuilder(query QueryMap, mod QueryModificator) builders.Builder {
 	qb := ds.adapter.Builder()
 	var fields []string
-	mod := parseParams(params)
-	if len(mod.fields) == 0 {
+	if len(mod.fields) == 0 && ds.model != nil {
 		fields = lib.GetStructTags(reflect.ValueOf(ds.model).Elem(), "db", true)
-	} else {
+	} else if len(mod.fields) > 0 {
 		fields = mod.fields
 	}
 	if mod.limit == 0 {
 		mod.limit = defaultLimit
 	}
-	qb.Select(fields).
+	qb = qb.Select(fields).
 		From(ds.source).
 		Where(query).
 		Limit(mod.limit, mod.skip)
 
-	if len(ds.joinedRepositories) > 0 {
-		for _, j := range ds.joinedRepositories {
-			qb.Join(j)
-		}
+	for _, j := range ds.joins() {
+		qb = qb.Join(j)
 	}
 
 	if len(mod.orderBy) > 0 {
 		for _, o := range mod.orderBy {
-			qb.Order(o)
+			qb = qb.Order(o)
 		}
 	}
 
-	SQL := qb.Build()
+	return qb
+}
+
+// build - building query and printing it in debug mode (name is operation for output)
+func (ds *Postgres) build(name string, b builders.Builder) (string, []interface{}, error) {
+	SQL, args, err := b.Build()
+	if err != nil {
+		return "", nil, vodka.NewServerError("invalid_query", err.Error())
+	}
 	if ds.debug {
-		fmt.Println("Fetch SQL: ", SQL)
+		fmt.Println(name+" SQL: ", ds.debugSQL(b, SQL, args))
 	}
-	rows, err := ds.adapter.Query(SQL)
+	return SQL, args, nil
+}
+
+func (ds *Postgres) buildResult(rows *sql.Rows) ([]interface{}, error) {
+	var result []interface{}
+	err := ds.scanRows(rows, func(item interface{}) error {
+		result = append(result, item)
+		return nil
+	})
 	if err != nil {
-		fmt.Println("Error: ", err)
 		return nil, err
 	}
-	defer rows.Close()
-	return ds.buildResult(rows)
+	return result, nil
 }
 
-func (ds *Postgres) buildResult(rows *sql.Rows) ([]interface{}, error) {
-	var result []interface{}
-	i := 0
+// scanRows - scanning rows one by one into model items (maps in dynamic mode) passed to fn
+func (ds *Postgres) scanRows(rows *sql.Rows, fn func(interface{}) error) error {
 	cols, _ := rows.Columns()
+	types, _ := rows.ColumnTypes()
 	dest := make([]interface{}, len(cols))
 	rawResult := make([]interface{}, len(cols))
 
@@ -301,13 +866,16 @@ func (ds *Postgres) buildResult(rows *sql.Rows) ([]interface{}, error) {
 
 	for rows.Next() {
 		data := make(map[string]interface{})
-		i++
 		if err := rows.Scan(dest...); err != nil {
 			fmt.Println("Error: ", err)
-			return nil, err
+			return err
 		}
 		for key, v := range cols {
-			if a, ok := rawResult[key].([]byte); ok == true {
+			if ds.model == nil && key < len(types) {
+				data[v] = adapters.ConvertValue(types[key].DatabaseTypeName(), rawResult[key])
+			} else if a, ok := rawResult[key].([]byte); ok && key < len(types) && strings.HasPrefix(types[key].DatabaseTypeName(), "_") {
+				data[v] = adapters.ConvertValue(types[key].DatabaseTypeName(), a)
+			} else if a, ok := rawResult[key].([]byte); ok == true {
 				// data[v] = string(a)
 				f, e := strconv.ParseFloat(string(a), 64)
 				if e != nil {
@@ -319,18 +887,30 @@ func (ds *Postgres) buildResult(rows *sql.Rows) ([]interface{}, error) {
 				data[v] = rawResult[key]
 			}
 		}
+		joined := ds.splitJoined(data)
+		var item interface{}
 		if ds.model != nil {
-			m := reflect.ValueOf(ds.model)
-			a := populateStructByMap(m, data)
-			result = append(result, a)
+			// every row gets own value, model is shared by concurrent reads
+			m := reflect.New(reflect.TypeOf(ds.model).Elem())
+			item = setJoined(populateStructByMap(m, data), joined)
 		} else {
-			result = append(result, data)
+			flattenJoined(data, joined)
+			item = data
+		}
+		if err := fn(item); err != nil {
+			return err
 		}
 	}
-	return result, nil
+	return rows.Err()
 }
 
 func (ds *Postgres) mapCollection(data []interface{}) (interface{}, error) {
+	if m, ok := ds.mapper.(ConcurrentMapper); ok && m.Concurrency() > 1 {
+		return mapConcurrently(data, m.Concurrency(), ds.mapItem)
+	}
+	if m, ok := ds.mapper.(ContextMapper); ok {
+		return m.CollectionContext(ds.Context(), data)
+	}
 	if ds.mapper != nil {
 		return ds.mapper.Collection(data)
 	}
@@ -339,8 +919,128 @@ func (ds *Postgres) mapCollection(data []interface{}) (interface{}, error) {
 }
 
 func (ds *Postgres) mapItem(data interface{}) (interface{}, error) {
+	if m, ok := ds.mapper.(ContextMapper); ok {
+		return m.ItemContext(ds.Context(), data)
+	}
 	if ds.mapper != nil {
 		return ds.mapper.Item(data)
 	}
 	return data, nil
 }
+
+// QueryScalar - single value lookup with raw SQL (see adapters.QueryScalar)
+func (ds *Postgres) QueryScalar(SQL string, args ...interface{}) (interface{}, error) {
+	return adapters.QueryScalar(ds.adapter, SQL, args...)
+}
+
+// QueryColumn - scanning one column into typed slice (see adapters.QueryColumn)
+func (ds *Postgres) QueryColumn(dest interface{}, SQL string, args ...interface{}) error {
+	return adapters.QueryColumn(ds.adapter, dest, SQL, args...)
+}
+
+// QueryMapRows - fetching rows as maps bypassing model and mapper (see adapters.QueryMapRows)
+func (ds *Postgres) QueryMapRows(SQL string, args ...interface{}) ([]map[string]interface{}, error) {
+	return adapters.QueryMapRows(ds.adapter, SQL, args...)
+}
+
+/*
+Query - running raw parameterized SQL, rows are hydrated into model (maps in dynamic mode)
+and passed through mapper like Find result
+*/
+func (ds *Postgres) Query(SQL string, args ...interface{}) (interface{}, error) {
+	data, err := ds.queryRaw(SQL, args)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ds.mapCollection(data)
+	if d, ok := result.([]interface{}); ok && len(d) == 0 {
+		result = make([]int, 0)
+	}
+	return result, err
+}
+
+/*
+QueryOne - first row of raw SQL as mapped item, not found error if there are no rows
+*/
+func (ds *Postgres) QueryOne(SQL string, args ...interface{}) (interface{}, error) {
+	data, err := ds.queryRaw(SQL, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, vodka.NewNotFoundError("not_found", "Item not found")
+	}
+	return ds.mapItem(data[0])
+}
+
+func (ds *Postgres) queryRaw(SQL string, args []interface{}) ([]interface{}, error) {
+	if ds.debug {
+		fmt.Println("Query SQL: ", SQL)
+	}
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return ds.buildResult(rows)
+}
+
+/*
+WithClass - copy of repository running queries as class (e.g. adapters.ClassBatch).
+Works only if repository adapter is adapters.Classified, otherwise returns repository as is
+*/
+func (ds *Postgres) WithClass(class adapters.QueryClass) *Postgres {
+	a, ok := ds.adapter.(*adapters.Classified)
+	if !ok {
+		return ds
+	}
+	c := *ds
+	c.adapter = a.Class(class)
+	return &c
+}
+
+/*
+Upsert - inserting data (map or model struct) or updating existing row conflicting
+by conflictColumns, primary key if empty (INSERT ... ON CONFLICT DO UPDATE).
+autoCreateTime columns of existing row are kept. Returns resulting row through mapper and profile, as Create does
+*/
+func (ds *Postgres) Upsert(data interface{}, conflictColumns []string) (interface{}, error) {
+	data, err := ds.runHooks(BeforeCreate, data)
+	if err != nil {
+		return nil, err
+	}
+	item, err := ds.upsert(data, conflictColumns)
+	if err != nil {
+		return item, err
+	}
+	return ds.runHooks(AfterCreate, item)
+}
+
+func (ds *Postgres) upsert(data interface{}, conflictColumns []string) (interface{}, error) {
+	payload, _, err := ds.prepareCreate(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflictColumns) == 0 {
+		conflictColumns = ds.keyColumns()
+	}
+	builder := ds.adapter.Builder().Insert(ds.source).Values(ds.insertRow(payload)).OnConflict(conflictColumns, builders.ConflictDoUpdate).KeepOnConflict(ds.autoCreate).ReturnID("*")
+	SQL, args, err := ds.build("Upsert", builder)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := ds.adapter.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ds.invalidate(nil)
+	items, err := ds.buildResult(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return payload, nil
+	}
+	return ds.output(items[0])
+}
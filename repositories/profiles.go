@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/niklucky/vodka"
+)
+
+/*
+SetProfile - serialization profile (e.g. "public", "admin") emitting only listed fields.
+Profiles could also be declared with model tags: `profile:"public,admin"`,
+fields without tag are emitted in every profile. Fields set here override tags.
+Profile is selected with WithProfile or params["profile"] in Find
+*/
+func (ds *Postgres) SetProfile(name string, fields ...string) {
+	if ds.profiles == nil {
+		ds.profiles = make(map[string][]string)
+	}
+	ds.profiles[name] = fields
+}
+
+/*
+WithProfile - copy of repository serializing results with profile
+*/
+func (ds *Postgres) WithProfile(name string) *Postgres {
+	c := *ds
+	c.profile = name
+	return &c
+}
+
+// profileFields - output keys of profile from config or model tags (json names)
+func (ds *Postgres) profileFields(name string) (map[string]bool, error) {
+	fields := make(map[string]bool)
+	if list, ok := ds.profiles[name]; ok {
+		for _, f := range list {
+			fields[f] = true
+		}
+		return fields, nil
+	}
+	declared := false
+	if ds.model != nil {
+		st := reflect.ValueOf(ds.model).Elem().Type()
+		for i := 0; i < st.NumField(); i++ {
+			field := st.Field(i)
+			key := jsonName(field)
+			if key == "" {
+				continue
+			}
+			tag := field.Tag.Get("profile")
+			if tag == "" {
+				fields[key] = true
+				continue
+			}
+			for _, p := range strings.Split(tag, ",") {
+				if strings.TrimSpace(p) == name {
+					fields[key] = true
+					declared = true
+				}
+			}
+		}
+	}
+	if !declared {
+		return nil, vodka.NewBadRequestError("unknown_profile", "Profile "+name+" is not defined for "+ds.source)
+	}
+	return fields, nil
+}
+
+// jsonName - key of struct field in JSON output, empty if field is skipped
+func jsonName(field reflect.StructField) string {
+	tag := strings.Split(field.Tag.Get("json"), ",")[0]
+	if tag == "-" || field.PkgPath != "" {
+		return ""
+	}
+	if tag != "" {
+		return tag
+	}
+	return field.Name
+}
+
+// applyProfile - mapped result (item or collection) as maps with profile fields only
+func (ds *Postgres) applyProfile(name string, result interface{}) (interface{}, error) {
+	if name == "" || result == nil {
+		return result, nil
+	}
+	fields, err := ds.profileFields(name)
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Slice {
+		return filterFields(result, fields)
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		if items[i], err = filterFields(rv.Index(i).Interface(), fields); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func filterFields(item interface{}, fields map[string]bool) (map[string]interface{}, error) {
+	dto, err := toDTO(item)
+	if err != nil {
+		return nil, err
+	}
+	for key := range dto {
+		if !fields[key] {
+			delete(dto, key)
+		}
+	}
+	return dto, nil
+}
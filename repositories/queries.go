@@ -0,0 +1,366 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/niklucky/vodka"
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+// queryNameMarker - starts named query in .sql file with several queries
+const queryNameMarker = "-- name:"
+
+/*
+NamedQuery - SQL query loaded from file. Params are names of :param placeholders
+in order of appearance (repeated if used several times)
+*/
+type NamedQuery struct {
+	Name   string
+	SQL    string
+	Params []string
+}
+
+/*
+Queries - named queries loaded with LoadQueries
+*/
+type Queries struct {
+	adapter adapters.Adapter
+	queries map[string]NamedQuery
+	debug   bool
+}
+
+/*
+LoadQueries - loading .sql files from dir as named queries.
+File is single query named after file (find_user.sql -> find_user)
+or several queries each starting with comment "-- name: find_user".
+Named params (:user_id) are replaced by placeholders of adapter builder.
+If repositories are passed, tables referenced by queries have to be their sources
+*/
+func LoadQueries(adapter adapters.Adapter, dir string, repos map[string]*Postgres) (*Queries, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	q := &Queries{adapter: adapter, queries: make(map[string]NamedQuery), debug: isDebug()}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		for name, text := range splitQueries(name, string(data)) {
+			if _, ok := q.queries[name]; ok {
+				return nil, fmt.Errorf("queries %s: query %s is defined twice", file, name)
+			}
+			nq, err := parseQuery(adapter.Builder(), name, text)
+			if err != nil {
+				return nil, fmt.Errorf("queries %s: %v", file, err)
+			}
+			if err := nq.validate(repos); err != nil {
+				return nil, fmt.Errorf("queries %s: %v", file, err)
+			}
+			q.queries[name] = nq
+		}
+	}
+	return q, nil
+}
+
+// splitQueries - queries of file by name
+func splitQueries(fileName, text string) map[string]string {
+	queries := make(map[string]string)
+	if !strings.Contains(text, queryNameMarker) {
+		queries[fileName] = text
+		return queries
+	}
+	var name string
+	for _, line := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, queryNameMarker) {
+			name = strings.TrimSpace(strings.TrimPrefix(trimmed, queryNameMarker))
+			continue
+		}
+		if name != "" {
+			queries[name] += line + "\n"
+		}
+	}
+	return queries
+}
+
+// parseQuery - replacing :params by placeholders.
+// Casts (::int), string literals, quoted identifiers and comments are skipped
+func parseQuery(b builders.Builder, name, text string) (nq NamedQuery, err error) {
+	nq.Name = name
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(text[i+1:], c)
+			if end == -1 {
+				return nq, fmt.Errorf("query %s: unterminated quote", name)
+			}
+			out.WriteString(text[i : i+end+2])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(text[i:], "--"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end == -1 {
+				end = len(text) - i
+			}
+			out.WriteString(text[i : i+end])
+			i += end - 1
+		case c == ':' && strings.HasPrefix(text[i:], "::"):
+			out.WriteString("::")
+			i++
+		case c == ':' && i+1 < len(text) && isParamStart(text[i+1]):
+			j := i + 1
+			for j < len(text) && (isParamStart(text[j]) || (text[j] >= '0' && text[j] <= '9')) {
+				j++
+			}
+			nq.Params = append(nq.Params, text[i+1:j])
+			out.WriteString(builders.Placeholder(b, len(nq.Params)))
+			i = j - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	nq.SQL = strings.TrimSpace(out.String())
+	if nq.SQL == "" {
+		return nq, fmt.Errorf("query %s is empty", name)
+	}
+	return nq, nil
+}
+
+func isParamStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// validate - checking that query references only sources of repositories
+func (nq NamedQuery) validate(repos map[string]*Postgres) error {
+	if len(repos) == 0 {
+		return nil
+	}
+	sources := make(map[string]bool)
+	for _, repo := range repos {
+		sources[repo.Source()] = true
+	}
+	for _, ref := range tableRefs(nq.SQL) {
+		table := ref
+		if i := strings.LastIndex(table, "."); i != -1 {
+			// schema qualified
+			table = table[i+1:]
+		}
+		if !sources[table] {
+			return fmt.Errorf("query %s: table %s is not source of any repository", nq.Name, ref)
+		}
+	}
+	return nil
+}
+
+/*
+tableRefs - tables referenced by query: FROM and JOIN in query or subquery (not in function
+arguments like EXTRACT(YEAR FROM x) or after IS DISTINCT), INSERT/MERGE INTO and UPDATE statement.
+Names of CTEs and table functions (FROM unnest(...)) are skipped
+*/
+func tableRefs(SQL string) (tables []string) {
+	tokens := sqlTokens(SQL)
+	ctes := make(map[string]bool)
+	// kinds of open parentheses, true for function call
+	var calls []bool
+	withDepth := -1
+	expectCTE := false
+	for i, token := range tokens {
+		word := strings.ToUpper(token)
+		prev, next := "", ""
+		if i > 0 {
+			prev = strings.ToUpper(tokens[i-1])
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+		switch {
+		case token == "(":
+			calls = append(calls, isIdentToken(prev) && !sqlKeywords[prev])
+			continue
+		case token == ")":
+			if len(calls) > 0 {
+				calls = calls[:len(calls)-1]
+			}
+			if len(calls) == withDepth && !strings.EqualFold(next, "AS") {
+				// CTE body is closed: next CTE of WITH list or statement
+				expectCTE = next == ","
+				if !expectCTE {
+					withDepth = -1
+				}
+			}
+			continue
+		case word == "WITH":
+			withDepth, expectCTE = len(calls), true
+			continue
+		case expectCTE && word != "RECURSIVE" && word != ",":
+			if isIdentToken(word) {
+				ctes[strings.ToLower(token)] = true
+			}
+			expectCTE = false
+			continue
+		}
+		if len(calls) > 0 && calls[len(calls)-1] {
+			// FROM in function arguments
+			continue
+		}
+		var ref bool
+		switch word {
+		case "FROM":
+			ref = prev != "DISTINCT"
+		case "JOIN":
+			ref = true
+		case "INTO":
+			ref = prev == "INSERT" || prev == "MERGE"
+		case "UPDATE":
+			ref = prev != "DO" && prev != "FOR" && prev != "KEY" && prev != "ON"
+		}
+		if !ref || i+1 >= len(tokens) {
+			continue
+		}
+		j := i + 1
+		if upper := strings.ToUpper(tokens[j]); (upper == "ONLY" || upper == "LATERAL") && j+1 < len(tokens) {
+			j++
+		}
+		table := tokens[j]
+		if !isIdentToken(table) || sqlKeywords[strings.ToUpper(table)] || ctes[strings.ToLower(table)] {
+			continue
+		}
+		if (word == "FROM" || word == "JOIN") && j+1 < len(tokens) && tokens[j+1] == "(" {
+			// table function
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return
+}
+
+// sqlKeywords - keywords preceding parentheses that are not function calls and can't be table names
+var sqlKeywords = map[string]bool{
+	"ALL": true, "AND": true, "ANY": true, "AS": true, "EXISTS": true, "FROM": true, "IN": true, "INTO": true,
+	"JOIN": true, "LATERAL": true, "NOT": true, "ON": true, "OR": true, "SELECT": true, "SET": true, "SOME": true,
+	"UNION": true, "USING": true, "VALUES": true, "WHERE": true, "WITH": true, "EXCEPT": true, "INTERSECT": true,
+	"MATERIALIZED": true, "RETURNING": true,
+}
+
+// sqlTokens - words (identifiers, keywords, quoted identifiers without quotes), parentheses and commas
+// of query. Literals, placeholders, operators and comments are skipped
+func sqlTokens(SQL string) (tokens []string) {
+	for i := 0; i < len(SQL); i++ {
+		c := SQL[i]
+		switch {
+		case c == '-' && strings.HasPrefix(SQL[i:], "--"):
+			end := strings.IndexByte(SQL[i:], '\n')
+			if end == -1 {
+				return
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(SQL[i:], "/*"):
+			end := strings.Index(SQL[i:], "*/")
+			if end == -1 {
+				return
+			}
+			i += end + 1
+		case c == '\'':
+			for i++; i < len(SQL); i++ {
+				if SQL[i] == '\'' {
+					if i+1 < len(SQL) && SQL[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case c == '"':
+			end := strings.IndexByte(SQL[i+1:], '"')
+			if end == -1 {
+				return
+			}
+			tokens = append(tokens, SQL[i+1:i+1+end])
+			i += end + 1
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+		case isParamStart(c):
+			j := i
+			for j < len(SQL) && (isParamStart(SQL[j]) || SQL[j] >= '0' && SQL[j] <= '9' || SQL[j] == '.' || SQL[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, SQL[i:j])
+			i = j - 1
+		case c == '$' || c >= '0' && c <= '9':
+			// placeholder or number
+			for i+1 < len(SQL) && (SQL[i+1] >= '0' && SQL[i+1] <= '9' || SQL[i+1] == '.') {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			tokens = append(tokens, string(c))
+		}
+	}
+	return
+}
+
+func isIdentToken(token string) bool {
+	return token != "" && (isParamStart(token[0]) || len(token) > 1 || token[0] >= 0x80)
+}
+
+// Names - names of loaded queries
+func (q *Queries) Names() []string {
+	var names []string
+	for name := range q.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+Query - running named query with params and returning rows as maps
+*/
+func (q *Queries) Query(name string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	SQL, args, err := q.bind(name, params)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.QueryMapRows(q.adapter, SQL, args...)
+}
+
+/*
+Exec - running named query (INSERT/UPDATE/DELETE) with params
+*/
+func (q *Queries) Exec(name string, params map[string]interface{}) (sql.Result, error) {
+	SQL, args, err := q.bind(name, params)
+	if err != nil {
+		return nil, err
+	}
+	return q.adapter.Exec(SQL, args...)
+}
+
+// bind - SQL of named query and arguments in order of placeholders
+func (q *Queries) bind(name string, params map[string]interface{}) (string, []interface{}, error) {
+	nq, ok := q.queries[name]
+	if !ok {
+		return "", nil, vodka.NewServerError("unknown_query", "Query "+name+" is not loaded")
+	}
+	args := make([]interface{}, len(nq.Params))
+	for i, param := range nq.Params {
+		v, ok := params[param]
+		if !ok {
+			return "", nil, vodka.NewBadRequestError("missing_param", "Query "+name+" requires param "+param)
+		}
+		args[i] = v
+	}
+	if q.debug {
+		fmt.Println("Query "+name+" SQL: ", nq.SQL, args)
+	}
+	return nq.SQL, args, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/niklucky/vodka/builders"
+)
+
+func TestSplitQueries(t *testing.T) {
+	single := splitQueries("find_user", "SELECT * FROM users WHERE id = :id\n")
+	if !reflect.DeepEqual(single, map[string]string{"find_user": "SELECT * FROM users WHERE id = :id\n"}) {
+		t.Errorf("single query %#v", single)
+	}
+	text := "-- comment of file\n-- name: active\nSELECT * FROM users\nWHERE active\n  -- name: by_id  \nSELECT * FROM users WHERE id = :id\n"
+	want := map[string]string{
+		"active": "SELECT * FROM users\nWHERE active\n",
+		"by_id":  "SELECT * FROM users WHERE id = :id\n\n",
+	}
+	if got := splitQueries("users", text); !reflect.DeepEqual(got, want) {
+		t.Errorf("named queries %#v, want %#v", got, want)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		text   string
+		SQL    string
+		params []string
+	}{
+		{"SELECT * FROM users WHERE id = :id AND org = :org_id", "SELECT * FROM users WHERE id = $1 AND org = $2", []string{"id", "org_id"}},
+		{"SELECT :id::int, :id", "SELECT $1::int, $2", []string{"id", "id"}},
+		{"SELECT ':skip', \"col:x\" -- :comment\nFROM t", "SELECT ':skip', \"col:x\" -- :comment\nFROM t", nil},
+		{"SELECT '12:30'::time", "SELECT '12:30'::time", nil},
+	}
+	for _, tt := range tests {
+		nq, err := parseQuery(builders.NewPostgres(), "q", tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nq.SQL != tt.SQL || !reflect.DeepEqual(nq.Params, tt.params) {
+			t.Errorf("parseQuery(%q) = %q %v, want %q %v", tt.text, nq.SQL, nq.Params, tt.SQL, tt.params)
+		}
+	}
+	for _, text := range []string{"  \n", "SELECT 'x"} {
+		if _, err := parseQuery(builders.NewPostgres(), "q", text); err == nil {
+			t.Errorf("parseQuery(%q): no error", text)
+		}
+	}
+}
+
+func TestTableRefs(t *testing.T) {
+	tests := []struct {
+		SQL    string
+		tables []string
+	}{
+		{"SELECT * FROM users u JOIN orders o ON o.user_id = u.id", []string{"users", "orders"}},
+		{"SELECT * FROM public.users WHERE id IN (SELECT user_id FROM orders)", []string{"public.users", "orders"}},
+		{"INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name", []string{"users"}},
+		{"SELECT EXTRACT(YEAR FROM created_at), SUBSTRING(name FROM 2) FROM users", []string{"users"}},
+		{"SELECT * FROM users WHERE a IS DISTINCT FROM b AND c IS NOT DISTINCT FROM d", []string{"users"}},
+		{"WITH recent AS (SELECT * FROM orders), big (id) AS (SELECT id FROM recent) SELECT * FROM recent JOIN big USING (id)", []string{"orders"}},
+		{"UPDATE users SET name = $1 WHERE id = $2", []string{"users"}},
+		{"SELECT * FROM users FOR UPDATE", []string{"users"}},
+		{"SELECT * FROM unnest($1::int[]) AS x JOIN users ON users.id = x", []string{"users"}},
+		{"SELECT coalesce((SELECT max(total) FROM orders), 0) FROM users", []string{"orders", "users"}},
+		{"SELECT 'FROM secrets' FROM users -- FROM secrets\n/* JOIN secrets */", []string{"users"}},
+		{"SELECT * FROM \"Users\"", []string{"Users"}},
+	}
+	for _, tt := range tests {
+		if got := tableRefs(tt.SQL); !reflect.DeepEqual(got, tt.tables) {
+			t.Errorf("tableRefs(%q) = %v, want %v", tt.SQL, got, tt.tables)
+		}
+	}
+}
+
+func TestNamedQueryValidate(t *testing.T) {
+	repos := map[string]*Postgres{"users": NewPostgres(nil, "users", nil), "orders": NewPostgres(nil, "orders", nil)}
+	valid := []string{
+		"INSERT INTO users (id, name) VALUES (:id, :name) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name",
+		"SELECT EXTRACT(YEAR FROM created_at) AS year, count(*) FROM orders GROUP BY 1",
+	}
+	for _, SQL := range valid {
+		if err := (NamedQuery{Name: "q", SQL: SQL}).validate(repos); err != nil {
+			t.Errorf("%q: %v", SQL, err)
+		}
+	}
+	if err := (NamedQuery{Name: "q", SQL: "SELECT * FROM secrets"}).validate(repos); err == nil {
+		t.Error("unknown table: no error")
+	}
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/niklucky/vodka"
+	"github.com/niklucky/vodka/adapters"
+	"github.com/niklucky/vodka/builders"
+)
+
+// Kinds of relations
+const (
+	// RelationHasOne - single related row referencing this one (related.foreignKey = key)
+	RelationHasOne = "hasOne"
+	// RelationHasMany - related rows referencing this one (related.foreignKey = key)
+	RelationHasMany = "hasMany"
+	// RelationBelongsTo - related row this one references (foreignKey = related.key)
+	RelationBelongsTo = "belongsTo"
+)
+
+/*
+Association - related repository loaded with Preload: rows where TargetKey
+is one of Key values of parent rows
+*/
+type Association struct {
+	Kind      string
+	Repo      *Postgres
+	Key       string
+	TargetKey string
+}
+
+/*
+HasOne - declaring relation with single row of repo referencing rows of repository by foreignKey
+*/
+func (ds *Postgres) HasOne(name string, repo *Postgres, foreignKey string) {
+	ds.relate(name, Association{Kind: RelationHasOne, Repo: repo, Key: ds.keyColumn(), TargetKey: foreignKey})
+}
+
+/*
+HasMany - declaring relation with rows of repo referencing rows of repository by foreignKey
+*/
+func (ds *Postgres) HasMany(name string, repo *Postgres, foreignKey string) {
+	ds.relate(name, Association{Kind: RelationHasMany, Repo: repo, Key: ds.keyColumn(), TargetKey: foreignKey})
+}
+
+/*
+BelongsTo - declaring relation with row of repo referenced by foreignKey of repository rows
+*/
+func (ds *Postgres) BelongsTo(name string, repo *Postgres, foreignKey string) {
+	ds.relate(name, Association{Kind: RelationBelongsTo, Repo: repo, Key: foreignKey, TargetKey: repo.keyColumn()})
+}
+
+func (ds *Postgres) relate(name string, r Association) {
+	if ds.relations == nil {
+		ds.relations = make(map[string]Association)
+	}
+	ds.relations[name] = r
+}
+
+/*
+preload - loading relations named in params["preload"] ("orders", "orders,author" or []string)
+for rows with one query per relation. Related rows are set into model fields tagged
+`db:"-" relation:"<name>"` (slice for HasMany, struct or pointer otherwise), map rows get them by name.
+Rows are copied, fetched slice could be shared by coalesced reads
+*/
+func (ds *Postgres) preload(rows []interface{}, value interface{}) ([]interface{}, error) {
+	var names []string
+	switch v := value.(type) {
+	case nil:
+		return rows, nil
+	case string:
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	case []string:
+		names = v
+	default:
+		return nil, vodka.NewBadRequestError("invalid_preload", fmt.Sprintf("Preload %v is not a list of relations", value))
+	}
+	rows = append([]interface{}(nil), rows...)
+	for _, name := range names {
+		r, ok := ds.relations[name]
+		if !ok {
+			return nil, vodka.NewBadRequestError("unknown_relation", "Relation "+name+" of "+ds.source+" is not declared")
+		}
+		if err := ds.preloadRelation(rows, name, r); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func (ds *Postgres) preloadRelation(rows []interface{}, name string, r Association) error {
+	keys := distinctKeys(rows, r.Key)
+	if len(keys) == 0 {
+		return nil
+	}
+	repo := r.Repo
+	if t, ok := ds.adapter.(*adapters.Tx); ok {
+		// related rows are read in transaction of parent ones, they could be uncommitted
+		repo = repo.bind(t)
+	}
+	builder := repo.selectBuilder(QueryMap{r.TargetKey: builders.In(keys)}, QueryModificator{}).Limit(0, 0)
+	SQL, args, err := repo.build("Preload", builder)
+	if err != nil {
+		return err
+	}
+	result, err := repo.adapter.Query(SQL, args...)
+	if err != nil {
+		return err
+	}
+	related, err := repo.buildResult(result)
+	result.Close()
+	if err != nil {
+		return err
+	}
+	groups := make(map[string][]interface{})
+	for _, item := range related {
+		if v, ok := fieldValue(item, r.TargetKey); ok {
+			groups[fmt.Sprint(v)] = append(groups[fmt.Sprint(v)], item)
+		}
+	}
+	for i, row := range rows {
+		key, ok := fieldValue(row, r.Key)
+		if !ok || key == nil {
+			continue
+		}
+		rows[i] = setRelation(row, name, r.Kind == RelationHasMany, groups[fmt.Sprint(key)])
+	}
+	return nil
+}
+
+// setRelation - row with related items set into map key or field tagged `relation:"name"`
+func setRelation(row interface{}, name string, many bool, items []interface{}) interface{} {
+	if row, ok := row.(map[string]interface{}); ok {
+		m := make(map[string]interface{}, len(row)+1)
+		for key, v := range row {
+			m[key] = v
+		}
+		if many {
+			if items == nil {
+				items = make([]interface{}, 0)
+			}
+			m[name] = items
+		} else if len(items) > 0 {
+			m[name] = items[0]
+		} else {
+			m[name] = nil
+		}
+		return m
+	}
+	v := reflect.New(reflect.TypeOf(row)).Elem()
+	v.Set(reflect.ValueOf(row))
+	if v.Kind() != reflect.Struct {
+		return row
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("relation") != name {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(field.Type(), 0, len(items))
+			for _, item := range items {
+				if e, ok := relationValue(item, field.Type().Elem()); ok {
+					slice = reflect.Append(slice, e)
+				}
+			}
+			field.Set(slice)
+		} else if len(items) > 0 {
+			if e, ok := relationValue(items[0], field.Type()); ok {
+				field.Set(e)
+			}
+		}
+	}
+	return v.Interface()
+}
+
+// relationValue - related item as value of field type (struct, pointer to struct or interface)
+func relationValue(item interface{}, t reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(item)
+	switch {
+	case v.Type().AssignableTo(t):
+		return v, true
+	case t.Kind() == reflect.Ptr && v.Type().AssignableTo(t.Elem()):
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, true
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type relOrder struct {
+	ID     int64 `db:"id"`
+	UserID int64 `db:"user_id"`
+}
+
+type relUser struct {
+	ID     int64      `db:"id"`
+	Orders []relOrder `db:"-" relation:"orders"`
+}
+
+func TestPreloadInTx(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "orders") {
+			return []string{"id", "user_id"}, [][]driver.Value{{int64(10), int64(1)}, {int64(11), int64(1)}}, nil
+		}
+		return []string{"id"}, [][]driver.Value{{int64(1)}, {int64(2)}}, nil
+	})
+	users := NewPostgres(a, "users", &relUser{})
+	users.HasMany("orders", NewPostgres(a, "orders", &relOrder{}), "user_id")
+
+	var result interface{}
+	err := users.WithTx(func(tx Recorder) error {
+		var err error
+		result, err = tx.Find(QueryMap{}, ParamsMap{"preload": "orders"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := result.([]interface{})
+	if len(items[0].(relUser).Orders) != 2 || items[1].(relUser).Orders == nil || len(items[1].(relUser).Orders) != 0 {
+		t.Fatalf("users %+v", items)
+	}
+	log := a.statements()
+	if len(log) != 4 || !strings.HasPrefix(log[2], "tx: ") || !strings.Contains(log[2], "orders") {
+		t.Fatalf("preload is not in transaction: %q", log)
+	}
+}
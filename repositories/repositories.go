@@ -6,6 +6,8 @@ import (
 
 const (
 	defaultLimit = 100
+	// defaultMultiWorkers - max number of concurrent queries of FindMulti
+	defaultMultiWorkers = 4
 )
 
 /*
@@ -15,7 +17,9 @@ type Recorder interface {
 	Join(source, key, targetKey, joinType string, fields []string)
 	Find(QueryMap, ParamsMap) (interface{}, error)
 	FindByID(interface{}) (interface{}, error)
+	Count(QueryMap) (int64, error)
 	Create(interface{}) (interface{}, error)
+	Upsert(interface{}, []string) (interface{}, error)
 	Delete(QueryMap) (interface{}, error)
 	DeleteByID(interface{}) (interface{}, error)
 	Update(QueryMap, map[string]interface{}) (interface{}, error)
@@ -31,14 +35,30 @@ ParamsMap - simple map key=value
 */
 type ParamsMap map[string]interface{}
 
+/*
+Result - Find response envelope with applied params.
+Returned by Find when repository envelope is on (SetEnvelope) or params["envelope"] is true.
+Count - number of returned items, Estimate - approximate number of all matching rows
+(set with params["estimate"] = true, see EstimateCount)
+*/
+type Result struct {
+	Items    interface{}           `json:"items"`
+	Count    int                   `json:"count"`
+	Limit    int                   `json:"limit"`
+	Skip     int                   `json:"skip"`
+	Order    []builders.OrderParam `json:"order,omitempty"`
+	Estimate int64                 `json:"estimate,omitempty"`
+}
+
 /*
 QueryModificator - modification of query
 */
 type QueryModificator struct {
-	fields  []string
-	skip    int
-	limit   int
-	orderBy []builders.OrderParam
+	fields   []string
+	skip     int
+	limit    int
+	orderBy  []builders.OrderParam
+	settings Settings
 }
 
 // Mapper - mapping interface
@@ -0,0 +1,71 @@
+package repositories
+
+/*
+CreateResult - result of single item insert in CreateEach
+*/
+type CreateResult struct {
+	Index int
+	Item  interface{}
+	Error error
+}
+
+/*
+CreateEach - inserting items one by one in single transaction.
+Every item is wrapped in savepoint, so failed item is rolled back alone
+and doesn't abort the batch. Returns per-item results (Item or Error)
+*/
+func (ds *Postgres) CreateEach(items []interface{}) ([]CreateResult, error) {
+	tx, err := ds.beginWrite()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]CreateResult, len(items))
+	for i, data := range items {
+		results[i].Index = i
+		if _, err := tx.Exec("SAVEPOINT create_item"); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		item, err := ds.createInTx(tx, data)
+		if err != nil {
+			results[i].Error = err
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT create_item"); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			continue
+		}
+		results[i].Item = item
+		if _, err := tx.Exec("RELEASE SAVEPOINT create_item"); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return results, tx.Commit()
+}
+
+// createInTx - inserting single item with RETURNING in transaction
+func (ds *Postgres) createInTx(tx writeTx, data interface{}) (interface{}, error) {
+	data, _, err := ds.prepareCreate(data)
+	if err != nil {
+		return nil, err
+	}
+	builder := ds.adapter.Builder().Insert(ds.source).Values(ds.insertRow(data)).ReturnID("*")
+	SQL, args, err := ds.build("CreateEach", builder)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items, err := ds.buildResult(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return data, nil
+	}
+	return ds.mapItem(items[0])
+}
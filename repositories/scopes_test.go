@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/niklucky/vodka"
+)
+
+func TestUnknownScope(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	})
+	repo := NewPostgres(a, "items", nil)
+	repo.scopes = map[string]QueryMap{"active": {"deleted_at": nil}}
+	if _, err := repo.Find(QueryMap{}, ParamsMap{"scope": "active"}); err != nil {
+		t.Fatal(err)
+	}
+	if log := a.statements(); !strings.Contains(log[0], "deleted_at IS NULL") {
+		t.Fatalf("scope is not applied: %q", log)
+	}
+	for _, scope := range []interface{}{"actve", 1} {
+		_, err := repo.Find(QueryMap{}, ParamsMap{"scope": scope})
+		if e, ok := err.(vodka.Error); !ok || e.HTTPCode() != vodka.ErrorBadRequestCode || e.Message != "unknown_scope" {
+			t.Fatalf("scope %v: error %v, want bad request", scope, err)
+		}
+	}
+	if _, err := repo.FindOne(QueryMap{}, ParamsMap{"scope": "actve"}); err == nil {
+		t.Fatal("FindOne: no error")
+	}
+	if len(a.statements()) != 1 {
+		t.Fatalf("statements %q", a.statements())
+	}
+}
+
+func TestCreateReadBackWithFilters(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id", "name"}, [][]driver.Value{{"k1", "a"}}, nil
+	})
+	repo := NewPostgres(a, "items", &uuidItem{})
+	// allowlist of callers' filters doesn't have key column
+	repo.filters = []string{"name"}
+	item, err := repo.Create(map[string]interface{}{"name": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.(uuidItem).ID != "k1" {
+		t.Fatalf("created %+v", item)
+	}
+}
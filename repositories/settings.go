@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"database/sql"
+	"sort"
+)
+
+/*
+Settings - planner settings (GUCs) for query: {"enable_seqscan": "off", "work_mem": "64MB", "jit": "off"}.
+Set for repository with WithSettings or for single Find with params["settings"]
+*/
+type Settings map[string]string
+
+// merge - settings overridden by query settings
+func (s Settings) merge(query Settings) Settings {
+	if len(query) == 0 {
+		return s
+	}
+	merged := make(Settings, len(s)+len(query))
+	for name, value := range s {
+		merged[name] = value
+	}
+	for name, value := range query {
+		merged[name] = value
+	}
+	return merged
+}
+
+/*
+WithSettings - copy of repository running reads with planner settings.
+Query is run in transaction with SET LOCAL, so settings don't leak to pooled connections
+(transaction of bound repository, settings are restored after query)
+*/
+func (ds *Postgres) WithSettings(settings Settings) *Postgres {
+	c := *ds
+	c.settings = c.settings.merge(settings)
+	return &c
+}
+
+func (ds *Postgres) queryWithSettings(settings Settings, SQL string, args []interface{}) ([]interface{}, error) {
+	var names []string
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// bound repository sets them in its transaction (Begin of transaction adapter fails)
+	tx, err := ds.beginWrite()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if !tx.own {
+		// transaction goes on after query, so previous values are restored
+		previous := make([]sql.NullString, len(names))
+		for i, name := range names {
+			if err := tx.QueryRow("SELECT current_setting($1, true)", name).Scan(&previous[i]); err != nil {
+				return nil, err
+			}
+		}
+		defer func() {
+			for i, name := range names {
+				tx.Exec("SELECT set_config($1, $2, true)", name, previous[i].String)
+			}
+		}()
+	}
+	for _, name := range names {
+		// same as SET LOCAL, but accepts parameters
+		if _, err := tx.Exec("SELECT set_config($1, $2, true)", name, settings[name]); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := tx.Query(SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ds.buildResult(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	return result, tx.Commit()
+}
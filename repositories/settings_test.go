@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestSettingsInTx(t *testing.T) {
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "current_setting"):
+			return []string{"current_setting"}, [][]driver.Value{{"on"}}, nil
+		case strings.Contains(query, "set_config"):
+			return []string{"set_config"}, [][]driver.Value{{args[1]}}, nil
+		}
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	})
+	repo := NewPostgres(a, "items", nil).WithSettings(Settings{"enable_seqscan": "off"})
+
+	// own transaction
+	if _, err := repo.Find(QueryMap{}, ParamsMap{}); err != nil {
+		t.Fatal(err)
+	}
+	if log := a.statements(); len(log) != 4 || log[0] != "BEGIN" || log[3] != "COMMIT" {
+		t.Fatalf("statements %q", log)
+	}
+
+	// transaction of bound repository
+	err := repo.WithTx(func(tx Recorder) error {
+		_, err := tx.Find(QueryMap{}, ParamsMap{})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := a.statements()[4:]
+	want := []string{"BEGIN", "tx: SELECT current_setting", "tx: SELECT set_config", "tx: SELECT t.", "tx: SELECT set_config", "COMMIT"}
+	if len(log) != len(want) {
+		t.Fatalf("statements %q", log)
+	}
+	for i := range want {
+		if !strings.HasPrefix(log[i], want[i]) {
+			t.Fatalf("statement %d is %q, want %q", i, log[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/niklucky/vodka/adapters"
+)
+
+/*
+Summary - declarative summary table (e.g. daily order totals) stored as materialized view
+— Name: name of materialized view
+— Query: SELECT that produces summary rows
+— Interval: period of scheduled refresh (see Start)
+— Concurrently: refresh without blocking reads (view has to have unique index)
+*/
+type Summary struct {
+	Name         string
+	Query        string
+	Interval     time.Duration
+	Concurrently bool
+}
+
+/*
+SummaryTable - repository bound to summary for fast reads plus maintenance methods.
+Summary is refreshed on schedule (Start) and/or after writes to tracked repositories (Track)
+*/
+type SummaryTable struct {
+	*Postgres
+	summary Summary
+	tracked int32
+	dirty   int32
+	stop    chan struct{}
+}
+
+/*
+NewSummary - summary table constructor. model describes summary rows
+*/
+func NewSummary(adapter adapters.Adapter, summary Summary, model interface{}) *SummaryTable {
+	return &SummaryTable{
+		Postgres: NewPostgres(adapter, summary.Name, model),
+		summary:  summary,
+	}
+}
+
+/*
+Create - creating materialized view if it doesn't exist
+*/
+func (s *SummaryTable) Create() error {
+	SQL := "CREATE MATERIALIZED VIEW IF NOT EXISTS " + s.summary.Name + " AS " + s.summary.Query
+	if s.debug {
+		fmt.Println("Summary SQL: ", SQL)
+	}
+	_, err := s.adapter.Exec(SQL)
+	return err
+}
+
+/*
+Refresh - recalculating summary
+*/
+func (s *SummaryTable) Refresh() error {
+	SQL := "REFRESH MATERIALIZED VIEW "
+	if s.summary.Concurrently {
+		SQL += "CONCURRENTLY "
+	}
+	SQL += s.summary.Name
+	if s.debug {
+		fmt.Println("Summary SQL: ", SQL)
+	}
+	atomic.StoreInt32(&s.dirty, 0)
+	_, err := s.adapter.Exec(SQL)
+	return err
+}
+
+/*
+Start - refreshing summary every Interval.
+If summary tracks repositories, refresh is done only if there were writes since last refresh
+*/
+func (s *SummaryTable) Start() {
+	if s.summary.Interval <= 0 || s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(s.summary.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if atomic.LoadInt32(&s.tracked) == 1 && atomic.LoadInt32(&s.dirty) == 0 {
+					continue
+				}
+				if err := s.Refresh(); err != nil {
+					fmt.Println("Summary refresh error: ", s.summary.Name, err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop)
+}
+
+/*
+Stop - stopping scheduled refresh
+*/
+func (s *SummaryTable) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+/*
+Track - wrapping repository of source table so its writes mark summary as outdated.
+Without Interval summary is refreshed right after every write
+*/
+func (s *SummaryTable) Track(repo Recorder) Recorder {
+	atomic.StoreInt32(&s.tracked, 1)
+	return &trackedRecorder{Recorder: repo, summary: s}
+}
+
+func (s *SummaryTable) markDirty() {
+	if s.summary.Interval > 0 {
+		atomic.StoreInt32(&s.dirty, 1)
+		return
+	}
+	if err := s.Refresh(); err != nil {
+		fmt.Println("Summary refresh error: ", s.summary.Name, err)
+	}
+}
+
+// trackedRecorder - repository that notifies summary about writes
+type trackedRecorder struct {
+	Recorder
+	summary *SummaryTable
+}
+
+func (r *trackedRecorder) track(result interface{}, err error) (interface{}, error) {
+	if err == nil {
+		r.summary.markDirty()
+	}
+	return result, err
+}
+
+func (r *trackedRecorder) Create(data interface{}) (interface{}, error) {
+	return r.track(r.Recorder.Create(data))
+}
+
+func (r *trackedRecorder) Upsert(data interface{}, conflictColumns []string) (interface{}, error) {
+	return r.track(r.Recorder.Upsert(data, conflictColumns))
+}
+
+func (r *trackedRecorder) Update(q QueryMap, payload map[string]interface{}) (interface{}, error) {
+	return r.track(r.Recorder.Update(q, payload))
+}
+
+func (r *trackedRecorder) Delete(q QueryMap) (interface{}, error) {
+	return r.track(r.Recorder.Delete(q))
+}
+
+func (r *trackedRecorder) DeleteByID(id interface{}) (interface{}, error) {
+	return r.track(r.Recorder.DeleteByID(id))
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/niklucky/vodka"
+)
+
+var (
+	timeBuckets  = []string{"second", "minute", "hour", "day", "week", "month", "quarter", "year"}
+	aggFunctions = []string{"SUM", "AVG", "MIN", "MAX", "COUNT"}
+)
+
+/*
+TimeRange - time interval for TimeSeries.
+Column is a timestamp column of source, From is inclusive and To is exclusive
+*/
+type TimeRange struct {
+	Column string
+	From   time.Time
+	To     time.Time
+}
+
+/*
+TimePoint - single bucket of time series
+*/
+type TimePoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+/*
+TimeSeries - aggregating metric column by time buckets.
+— bucket: date_trunc field (minute, hour, day, week, month...)
+— aggFn: SUM, AVG, MIN, MAX or COUNT
+Buckets without rows are filled with zeros
+*/
+func (ds *Postgres) TimeSeries(metric, bucket string, r TimeRange, aggFn string) ([]TimePoint, error) {
+	bucket = strings.ToLower(bucket)
+	aggFn = strings.ToUpper(aggFn)
+	if !inArray(bucket, timeBuckets) {
+		return nil, vodka.NewBadRequestError("invalid_bucket", bucket)
+	}
+	if !inArray(aggFn, aggFunctions) {
+		return nil, vodka.NewBadRequestError("invalid_aggregate", aggFn)
+	}
+	SQL := fmt.Sprintf(`SELECT s.bucket, COALESCE(d.value, 0)
+		FROM generate_series(date_trunc('%[1]s', $1::timestamptz), $2::timestamptz - interval '1 microsecond', interval '1 %[1]s') AS s(bucket)
+		LEFT JOIN (
+			SELECT date_trunc('%[1]s', t.%[2]s) AS bucket, %[3]s(t.%[4]s) AS value
+			FROM %[5]s AS t WHERE t.%[2]s >= $1 AND t.%[2]s < $2 GROUP BY 1
+		) AS d ON d.bucket = s.bucket
+		ORDER BY s.bucket`, bucket, r.Column, aggFn, metric, ds.source)
+	if ds.debug {
+		fmt.Println("TimeSeries SQL: ", SQL)
+	}
+	rows, err := ds.adapter.Query(SQL, r.From, r.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var points []TimePoint
+	for rows.Next() {
+		var p TimePoint
+		if err := rows.Scan(&p.Time, &p.Value); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+/*
+EnsureHypertable - converting source into TimescaleDB hypertable partitioned by timeColumn.
+Does nothing if source is hypertable already. Requires timescaledb extension
+*/
+func (ds *Postgres) EnsureHypertable(timeColumn string) error {
+	rows, err := ds.adapter.Query("SELECT create_hypertable($1, $2, if_not_exists => TRUE)", ds.source, timeColumn)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
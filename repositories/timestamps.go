@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"reflect"
+	"time"
+)
+
+/*
+getTimestampsByModel - columns of fields tagged `autoCreateTime:"true"` (set by Create)
+and `autoUpdateTime:"true"` (set by Create and Update)
+*/
+func getTimestampsByModel(model interface{}) (created, updated []string) {
+	if model == nil {
+		return
+	}
+	st := reflect.ValueOf(model).Elem().Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		name := field.Name
+		if field.Tag.Get("db") != "" {
+			name = field.Tag.Get("db")
+		}
+		if tag := field.Tag.Get("autoCreateTime"); tag != "" && tag != "false" {
+			created = append(created, name)
+		}
+		if tag := field.Tag.Get("autoUpdateTime"); tag != "" && tag != "false" {
+			updated = append(updated, name)
+		}
+	}
+	return
+}
+
+// stampCreate - create payload with auto timestamps set (values provided by caller are kept)
+func (ds *Postgres) stampCreate(data interface{}) interface{} {
+	columns := append(append([]string(nil), ds.autoCreate...), ds.autoUpdate...)
+	if len(columns) == 0 {
+		return data
+	}
+	now := time.Now()
+	if payload, ok := data.(map[string]interface{}); ok {
+		return stampMap(payload, columns, now)
+	}
+	rv := reflect.ValueOf(data)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return data
+	}
+	// struct is copied, so item of caller is not changed
+	item := reflect.New(rv.Type()).Elem()
+	item.Set(rv)
+	t := item.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if t.Field(i).Tag.Get("db") != "" {
+			name = t.Field(i).Tag.Get("db")
+		}
+		field := item.Field(i)
+		if inArray(name, columns) && field.CanSet() && field.Type() == reflect.TypeOf(now) && field.Interface().(time.Time).IsZero() {
+			field.Set(reflect.ValueOf(now))
+		}
+	}
+	return item.Interface()
+}
+
+// stampUpdate - update payload with autoUpdateTime columns set
+func (ds *Postgres) stampUpdate(payload map[string]interface{}) map[string]interface{} {
+	if len(ds.autoUpdate) == 0 {
+		return payload
+	}
+	return stampMap(payload, ds.autoUpdate, time.Now())
+}
+
+// stampMap - copy of payload with columns set to now (if they are not set)
+func stampMap(payload map[string]interface{}, columns []string, now time.Time) map[string]interface{} {
+	stamped := make(map[string]interface{}, len(payload)+len(columns))
+	for key, v := range payload {
+		stamped[key] = v
+	}
+	for _, column := range columns {
+		if _, ok := stamped[column]; !ok {
+			stamped[column] = now
+		}
+	}
+	return stamped
+}
@@ -0,0 +1,20 @@
+package repositories
+
+/*
+Truncate - removing all rows of repository source (test cleanup, bulk resets).
+Options are builders.TruncateCascade and builders.TruncateRestartIdentity (Postgres)
+*/
+func (ds *Postgres) Truncate(options ...string) error {
+	if err := ds.checkWritable(); err != nil {
+		return err
+	}
+	SQL, args, err := ds.build("Truncate", ds.adapter.Builder().Truncate(ds.source, options...))
+	if err != nil {
+		return err
+	}
+	if _, err := ds.adapter.Exec(SQL, args...); err != nil {
+		return err
+	}
+	ds.invalidate(nil)
+	return nil
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/niklucky/vodka/adapters"
+)
+
+/*
+Tx - repository bound to transaction: all its queries run in one transaction
+until Commit or Rollback (CreateMany/CreateEach join it instead of starting their own)
+*/
+type Tx struct {
+	*Postgres
+	tx *adapters.Tx
+}
+
+/*
+Begin - starting transaction, returns repository bound to it
+*/
+func (ds *Postgres) Begin() (*Tx, error) {
+	tx, err := adapters.NewTx(ds.adapter)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Postgres: ds.bind(tx), tx: tx}, nil
+}
+
+// bind - copy of repository running queries in transaction
+func (ds *Postgres) bind(tx *adapters.Tx) *Postgres {
+	c := *ds
+	c.adapter = tx
+	// reads of transaction see its uncommitted changes, so they are not shared
+	c.flights = nil
+	return &c
+}
+
+// Commit - committing transaction
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback - rolling transaction back
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+/*
+WithTx - running fn with repository bound to transaction.
+Transaction is committed if fn succeeds, rolled back if it returns error or panics
+*/
+func (ds *Postgres) WithTx(fn func(tx Recorder) error) error {
+	tx, err := ds.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%v (rollback: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// inTx - repository is bound to transaction
+func (ds *Postgres) inTx() bool {
+	_, ok := ds.adapter.(*adapters.Tx)
+	return ok
+}
+
+/*
+writeTx - transaction of multi-statement write (CreateMany, CreateEach): own one
+or transaction of bound repository, which is committed or rolled back by its owner
+*/
+type writeTx struct {
+	*sql.Tx
+	own bool
+}
+
+func (ds *Postgres) beginWrite() (writeTx, error) {
+	if t, ok := ds.adapter.(*adapters.Tx); ok {
+		return writeTx{Tx: t.SQLTx()}, nil
+	}
+	tx, err := ds.adapter.Begin()
+	return writeTx{Tx: tx, own: true}, err
+}
+
+func (t writeTx) Commit() error {
+	if !t.own {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t writeTx) Rollback() error {
+	if !t.own {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/niklucky/vodka/adapters"
+)
+
+/*
+UnitOfWork - transaction shared by several repositories of one database,
+e.g. order is created and inventory is decremented together or not at all:
+
+	err := repositories.WithUnitOfWork(adapter, func(u *repositories.UnitOfWork) error {
+		if _, err := u.Enlist(orders).Create(order); err != nil {
+			return err
+		}
+		_, err := u.Enlist(inventory).UpdateMany(query, payload)
+		return err
+	})
+*/
+type UnitOfWork struct {
+	tx *adapters.Tx
+}
+
+/*
+NewUnitOfWork - starting transaction of adapter. Enlisted repositories have to use
+the same database (adapter connection is not checked)
+*/
+func NewUnitOfWork(adapter adapters.Adapter) (*UnitOfWork, error) {
+	tx, err := adapters.NewTx(adapter)
+	if err != nil {
+		return nil, err
+	}
+	return &UnitOfWork{tx: tx}, nil
+}
+
+// Enlist - copy of repository running its queries in transaction of unit of work
+func (u *UnitOfWork) Enlist(repo *Postgres) *Postgres {
+	return repo.bind(u.tx)
+}
+
+// Commit - committing changes of all enlisted repositories
+func (u *UnitOfWork) Commit() error {
+	return u.tx.Commit()
+}
+
+// Rollback - rolling back changes of all enlisted repositories
+func (u *UnitOfWork) Rollback() error {
+	return u.tx.Rollback()
+}
+
+/*
+WithUnitOfWork - running fn in unit of work: committed if fn succeeds,
+rolled back if it returns error or panics
+*/
+func WithUnitOfWork(adapter adapters.Adapter, fn func(*UnitOfWork) error) error {
+	u, err := NewUnitOfWork(adapter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			u.Rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(u); err != nil {
+		if rbErr := u.Rollback(); rbErr != nil {
+			return fmt.Errorf("%v (rollback: %v)", err, rbErr)
+		}
+		return err
+	}
+	return u.Commit()
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/niklucky/vodka"
+)
+
+func TestUpdateSingleRow(t *testing.T) {
+	var updated []driver.Value
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.HasPrefix(query, "SELECT"):
+			if args[0] == "none" {
+				return []string{"id"}, nil, nil
+			}
+			return []string{"id"}, [][]driver.Value{{int64(7)}}, nil
+		case strings.HasPrefix(query, "UPDATE"):
+			updated = args
+			return []string{"id", "status"}, [][]driver.Value{{int64(7), "paid"}}, nil
+		}
+		return nil, nil, nil
+	})
+	repo := NewPostgres(a, "orders", nil)
+
+	// query by other columns updates first matching row only
+	if _, err := repo.Update(QueryMap{"status": "new"}, map[string]interface{}{"status": "paid"}); err != nil {
+		t.Fatal(err)
+	}
+	log := a.statements()
+	if len(log) != 2 || !strings.HasPrefix(log[0], "SELECT") || !strings.Contains(log[0], "LIMIT 1") {
+		t.Fatalf("statements: %q", log)
+	}
+	if !strings.Contains(log[1], "WHERE t.id=$2 AND t.status=$3") || len(updated) != 3 || updated[1] != int64(7) {
+		t.Fatalf("update is not limited to key: %q %v", log[1], updated)
+	}
+
+	// query by key is a single statement
+	if _, err := repo.Update(QueryMap{"id": 7}, map[string]interface{}{"status": "paid"}); err != nil {
+		t.Fatal(err)
+	}
+	if log := a.statements(); len(log) != 3 || !strings.HasPrefix(log[2], "UPDATE") {
+		t.Fatalf("statements: %q", log)
+	}
+
+	// nothing matched
+	if _, err := repo.Update(QueryMap{"status": "none"}, map[string]interface{}{"status": "paid"}); !vodka.IsNotFound(err) {
+		t.Fatalf("error %v, want not found", err)
+	}
+	if log := a.statements(); len(log) != 4 {
+		t.Fatalf("statements: %q", log)
+	}
+}
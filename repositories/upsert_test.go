@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type upsertItem struct {
+	ID     string `db:"id" uuid:"true" key:"true" json:"id"`
+	Name   string `db:"name" json:"name"`
+	Secret string `db:"secret" json:"secret"`
+}
+
+func TestUpsertOutput(t *testing.T) {
+	var SQL string
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		SQL = query
+		return []string{"id", "name", "secret"}, [][]driver.Value{{args[0], "a", "s"}}, nil
+	})
+	repo := NewPostgres(a, "items", &upsertItem{})
+	repo.SetProfile("public", "id", "name")
+	item, err := repo.WithProfile("public").Upsert(upsertItem{Name: "a", Secret: "s"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(SQL, "ON CONFLICT") {
+		t.Fatalf("SQL %q", SQL)
+	}
+	m, ok := item.(map[string]interface{})
+	if !ok || m["name"] != "a" || len(m["id"].(string)) != 36 {
+		t.Fatalf("upserted %#v", item)
+	}
+	if _, ok := m["secret"]; ok {
+		t.Fatalf("profile is not applied: %#v", m)
+	}
+}
+
+func TestUpsertExistingID(t *testing.T) {
+	const id = "11111111-1111-4111-8111-111111111111"
+	var args []driver.Value
+	a := newFakeAdapter(t, func(query string, a []driver.Value) ([]string, [][]driver.Value, error) {
+		args = a
+		return []string{"id", "name", "secret"}, [][]driver.Value{{a[0], "b", ""}}, nil
+	})
+	repo := NewPostgres(a, "items", &upsertItem{})
+	for _, data := range []interface{}{map[string]interface{}{"id": id, "name": "b"}, upsertItem{ID: id, Name: "b"}} {
+		item, err := repo.Upsert(data, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if args[0] != id {
+			t.Fatalf("%T: id is replaced, args %#v", data, args)
+		}
+		if item.(upsertItem).ID != id {
+			t.Fatalf("%T: upserted %+v", data, item)
+		}
+	}
+}
+
+type stampedItem struct {
+	ID        int64  `db:"id" key:"true"`
+	Name      string `db:"name"`
+	CreatedAt string `db:"created_at" autoCreateTime:"true"`
+	UpdatedAt string `db:"updated_at" autoUpdateTime:"true"`
+}
+
+func TestUpsertKeepsCreateTime(t *testing.T) {
+	var SQL string
+	a := newFakeAdapter(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		SQL = query
+		return []string{"id", "name"}, [][]driver.Value{{int64(1), "a"}}, nil
+	})
+	repo := NewPostgres(a, "items", &stampedItem{})
+	if _, err := repo.Upsert(map[string]interface{}{"id": 1, "name": "a"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	set := SQL[strings.Index(SQL, "DO UPDATE SET"):]
+	if strings.Contains(set, "created_at") || strings.Contains(set, "id =") || !strings.Contains(set, "updated_at = EXCLUDED.updated_at") {
+		t.Fatalf("SQL %q", SQL)
+	}
+	if !strings.Contains(SQL, "created_at") {
+		t.Fatalf("created_at is not inserted: %q", SQL)
+	}
+}
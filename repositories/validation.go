@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/niklucky/vodka"
+)
+
+/*
+fieldRule - validation of model field declared with tags:
+`required:"true"`, `min:"1"`, `max:"255"` (value of numbers, length of strings and lists),
+`regexp:"^[a-z]+$"` and `enum:"draft,published"`
+*/
+type fieldRule struct {
+	column   string
+	required bool
+	min, max *float64
+	pattern  *regexp.Regexp
+	enum     []string
+}
+
+// getRulesByModel - validation rules of model fields. Panics on invalid tag (programming error)
+func getRulesByModel(model interface{}) (rules []fieldRule) {
+	if model == nil {
+		return
+	}
+	st := reflect.ValueOf(model).Elem().Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		rule := fieldRule{column: field.Name, required: field.Tag.Get("required") == "true"}
+		if field.Tag.Get("db") != "" {
+			rule.column = field.Tag.Get("db")
+		}
+		rule.min = tagNumber(st, field, "min")
+		rule.max = tagNumber(st, field, "max")
+		if tag := field.Tag.Get("regexp"); tag != "" {
+			rule.pattern = regexp.MustCompile(tag)
+		}
+		if tag := field.Tag.Get("enum"); tag != "" {
+			rule.enum = strings.Split(tag, ",")
+		}
+		if rule.required || rule.min != nil || rule.max != nil || rule.pattern != nil || rule.enum != nil {
+			rules = append(rules, rule)
+		}
+	}
+	return
+}
+
+func tagNumber(st reflect.Type, field reflect.StructField, name string) *float64 {
+	tag := field.Tag.Get(name)
+	if tag == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(tag, 64)
+	if err != nil {
+		panic(fmt.Sprintf("%s.%s: invalid %s tag %q", st.Name(), field.Name, name, tag))
+	}
+	return &n
+}
+
+/*
+validate - checking payload (map or model struct) against model rules.
+Required fields are checked on create only, Update payload is partial.
+Zero fields of struct are missing for required rule, other rules check them.
+Returns 400 error with messages by column
+*/
+func (ds *Postgres) validate(data interface{}, create bool) error {
+	if len(ds.rules) == 0 {
+		return nil
+	}
+	payload, isMap := data.(map[string]interface{})
+	errs := make(map[string]string)
+	for _, rule := range ds.rules {
+		var v interface{}
+		var ok bool
+		if isMap {
+			v, ok = payload[rule.column]
+		} else if v, ok = fieldValue(data, rule.column); ok {
+			// zero field of struct is missing for required rule only, other rules check it as value
+			if rule.required && (v == nil || reflect.ValueOf(v).IsZero()) {
+				ok = false
+			} else if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+				v = nil
+				if !rv.IsNil() {
+					v = rv.Elem().Interface()
+				}
+			}
+		}
+		if !ok || v == nil {
+			if rule.required && (create || ok) {
+				errs[rule.column] = "is required"
+			}
+			continue
+		}
+		if msg := rule.check(v); msg != "" {
+			errs[rule.column] = msg
+		}
+	}
+	if len(errs) > 0 {
+		return vodka.NewBadRequestError("validation_failed", errs)
+	}
+	return nil
+}
+
+// check - message of first failed rule, empty if value is valid
+func (rule fieldRule) check(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	s, isString := v.(string)
+	if rule.required && isString && s == "" {
+		return "is required"
+	}
+	if size, ok := ruleSize(v); ok {
+		unit := ""
+		if isString {
+			unit = " characters"
+		}
+		if rule.min != nil && size < *rule.min {
+			return fmt.Sprintf("must be at least %v%s", *rule.min, unit)
+		}
+		if rule.max != nil && size > *rule.max {
+			return fmt.Sprintf("must be at most %v%s", *rule.max, unit)
+		}
+	}
+	if rule.pattern != nil && isString && !rule.pattern.MatchString(s) {
+		return "must match " + rule.pattern.String()
+	}
+	if rule.enum != nil && !inArray(fmt.Sprint(v), rule.enum) {
+		return "must be one of " + strings.Join(rule.enum, ", ")
+	}
+	return ""
+}
+
+// ruleSize - value compared with min/max: number itself, length of string or list
+func ruleSize(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		if n, ok := v.(interface{ Float64() (float64, error) }); ok {
+			f, err := n.Float64()
+			return f, err == nil
+		}
+		return float64(utf8.RuneCountInString(rv.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/niklucky/vodka"
+)
+
+type probeV struct {
+	ID     int64   `db:"id" key:"true"`
+	Name   string  `db:"name" required:"true" max:"5"`
+	Qty    int     `db:"qty" min:"1"`
+	Status string  `db:"status" enum:"draft,published"`
+	Rate   *int    `db:"rate" max:"10"`
+	Note   *string `db:"note" enum:"a,b"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	repo := NewPostgres(nil, "probes", &probeV{})
+	eleven, a, c := 11, "a", "c"
+	tests := []struct {
+		name   string
+		data   interface{}
+		failed []string
+	}{
+		{"valid", probeV{Name: "ann", Qty: 1, Status: "draft", Note: &a}, nil},
+		{"zero min", probeV{Name: "ann", Qty: 0, Status: "draft"}, []string{"qty"}},
+		{"zero enum", probeV{Name: "ann", Qty: 1}, []string{"status"}},
+		{"zero required", probeV{Qty: 1, Status: "draft"}, []string{"name"}},
+		{"pointers", probeV{Name: "ann", Qty: 1, Status: "draft", Rate: &eleven, Note: &c}, []string{"rate", "note"}},
+		{"map zero min", map[string]interface{}{"name": "ann", "qty": 0, "status": "draft"}, []string{"qty"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.validate(tt.data, true)
+			if len(tt.failed) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			e, ok := err.(vodka.Error)
+			if !ok || e.HTTPCode() != vodka.ErrorBadRequestCode {
+				t.Fatalf("error %v, want bad request", err)
+			}
+			failed, _ := e.Info.(map[string]string)
+			for _, column := range tt.failed {
+				if failed[column] == "" {
+					t.Errorf("%s is not failed: %v", column, failed)
+				}
+			}
+			if len(failed) != len(tt.failed) {
+				t.Errorf("failed %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
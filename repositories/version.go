@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/niklucky/vodka"
+)
+
+// getVersionByModel - column of field tagged `version:"true"` (optimistic locking)
+func getVersionByModel(model interface{}) string {
+	if model == nil {
+		return ""
+	}
+	st := reflect.ValueOf(model).Elem().Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if tag := field.Tag.Get("version"); tag == "" || tag == "false" {
+			continue
+		}
+		if field.Tag.Get("db") != "" {
+			return field.Tag.Get("db")
+		}
+		return field.Name
+	}
+	return ""
+}
+
+/*
+lockVersion - query and payload of versioned Update: row is matched by version from payload
+and version is incremented
+*/
+func (ds *Postgres) lockVersion(q QueryMap, payload map[string]interface{}) (QueryMap, map[string]interface{}, error) {
+	if ds.version == "" {
+		return q, payload, nil
+	}
+	v, ok := payload[ds.version]
+	if !ok {
+		return nil, nil, vodka.NewBadRequestError("version_required", "Field "+ds.version+" is required to update "+ds.source)
+	}
+	version, ok := versionNumber(v)
+	if !ok {
+		return nil, nil, vodka.NewBadRequestError("invalid_version", "Field "+ds.version+" has to be integer")
+	}
+	where := make(QueryMap, len(q)+1)
+	for key, v := range q {
+		where[key] = v
+	}
+	where[ds.version] = version
+	set := make(map[string]interface{}, len(payload))
+	for key, v := range payload {
+		set[key] = v
+	}
+	set[ds.version] = version + 1
+	return where, set, nil
+}
+
+// versionNumber - version value of payload (numbers of decoded JSON are float64)
+func versionNumber(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), n == float64(int64(n))
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}